@@ -11,8 +11,10 @@ CREATE TABLE IF NOT EXISTS players (
     id SERIAL,
     username VARCHAR(16) NOT NULL UNIQUE,
     password_hash TEXT NOT NULL,
-    password_salt BYTEA NOT NULL,
-    sso_token TEXT DEFAULT NULL,
+    password_salt BYTEA NOT NULL DEFAULT '', -- only used by legacy SHA-512 hashes
+    sso_token TEXT DEFAULT NULL UNIQUE,
+    sso_ip TEXT DEFAULT NULL,
+    sso_expires_at TIMESTAMP DEFAULT NULL,
     sex SEX NOT NULL DEFAULT 'F',
     figure TEXT NOT NULL DEFAULT '1000118001270012900121001',
     pool_figure TEXT NOT NULL DEFAULT '',
@@ -24,13 +26,93 @@ CREATE TABLE IF NOT EXISTS players (
     rank INT NOT NULL DEFAULT 1,
     birthday DATE NOT NULL,
     email TEXT NOT NULL,
-    sound_enabled BOOL NOT NULL DEFAULT true,
+    parent_confirmed BOOL NOT NULL DEFAULT true, -- false while an under age account waits for a parent to confirm it
     created_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
     last_online TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    online_seconds BIGINT NOT NULL DEFAULT 0, -- total time spent logged in
+    badge VARCHAR(3) NOT NULL DEFAULT '', -- code of the badge the player wears
+    badge_visible BOOL NOT NULL DEFAULT true,
     FOREIGN KEY (rank) REFERENCES player_ranks(id),
     PRIMARY KEY (id)
 );
 
+CREATE INDEX IF NOT EXISTS players_username_lower_idx ON players (LOWER(username));
+
+CREATE TABLE IF NOT EXISTS player_preferences (
+    player_id INT NOT NULL,
+    sound_enabled BOOL NOT NULL DEFAULT true,
+    direct_mail BOOL NOT NULL DEFAULT false,
+    accept_friend_requests BOOL NOT NULL DEFAULT true,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (player_id)
+);
+
+-- Databases created before player_preferences kept the sound setting on players, move it over.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'players' AND column_name = 'sound_enabled') THEN
+        INSERT INTO player_preferences (player_id, sound_enabled)
+            SELECT id, sound_enabled FROM players
+            ON CONFLICT (player_id) DO UPDATE SET sound_enabled = EXCLUDED.sound_enabled;
+        ALTER TABLE players DROP COLUMN sound_enabled;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS login_history (
+    id SERIAL,
+    player_id INT NOT NULL,
+    machine_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL,
+    logged_in_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    logged_out_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS login_history_player_idx ON login_history (player_id, logged_in_at);
+CREATE INDEX IF NOT EXISTS login_history_machine_idx ON login_history (machine_id);
+CREATE INDEX IF NOT EXISTS login_history_ip_idx ON login_history (ip_address);
+
+CREATE TABLE IF NOT EXISTS password_resets (
+    token TEXT NOT NULL,
+    player_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (token)
+);
+
+CREATE INDEX IF NOT EXISTS password_resets_player_idx ON password_resets (player_id);
+
+CREATE TABLE IF NOT EXISTS parent_confirmations (
+    token TEXT NOT NULL,
+    player_id INT NOT NULL,
+    parent_email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (token)
+);
+
+CREATE INDEX IF NOT EXISTS parent_confirmations_player_idx ON parent_confirmations (player_id);
+
+CREATE TYPE ban_type AS ENUM ('account', 'ip', 'machine');
+
+CREATE TABLE IF NOT EXISTS bans (
+    id SERIAL,
+    ban_type BAN_TYPE NOT NULL,
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    banned_by INT DEFAULT NULL,
+    banned_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires_at TIMESTAMP DEFAULT NULL,
+    lifted BOOL NOT NULL DEFAULT false,
+    FOREIGN KEY (banned_by) REFERENCES players(id) ON DELETE SET NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS bans_type_value_idx ON bans (ban_type, value);
+
 CREATE TABLE IF NOT EXISTS badges (
     id   SERIAL,
     code VARCHAR(3) UNIQUE NOT NULL,
@@ -40,12 +122,95 @@ CREATE TABLE IF NOT EXISTS badges (
 CREATE TABLE IF NOT EXISTS player_badges (
     player_id INT NOT NULL,
     badge_id INT NOT NULL,
-    display BOOL NOT NULL DEFAULT false,
     FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
-    FOREIGN KEY (badge_id) REFERENCES badges(id),
+    FOREIGN KEY (badge_id) REFERENCES badges(id) ON DELETE CASCADE,
     PRIMARY KEY (player_id, badge_id)
 );
 
+-- Badges owned by every player of a rank, without being given to them one by one.
+CREATE TABLE IF NOT EXISTS rank_badges (
+    rank INT NOT NULL,
+    badge_id INT NOT NULL,
+    FOREIGN KEY (rank) REFERENCES player_ranks(id) ON DELETE CASCADE,
+    FOREIGN KEY (badge_id) REFERENCES badges(id) ON DELETE CASCADE,
+    PRIMARY KEY (rank, badge_id)
+);
+
+-- Fuse rights granted to every player of min_rank or higher, only while they're club members if club_only is set.
+CREATE TABLE IF NOT EXISTS fuse_rights (
+    fuse TEXT NOT NULL,
+    min_rank INT NOT NULL,
+    club_only BOOL NOT NULL DEFAULT false,
+    FOREIGN KEY (min_rank) REFERENCES player_ranks(id) ON DELETE CASCADE,
+    PRIMARY KEY (fuse, min_rank, club_only)
+);
+
+-- Ledger of every change to player credits.
+CREATE TABLE IF NOT EXISTS credit_transactions (
+    id SERIAL,
+    player_id INT NOT NULL,
+    amount INT NOT NULL, -- negative when credits were spent
+    balance INT NOT NULL, -- the player's credits after the change
+    reason TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '', -- what the credits were spent on or came from
+    created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS credit_transactions_player_idx ON credit_transactions (player_id, created_at);
+
+CREATE TABLE IF NOT EXISTS vouchers (
+    code TEXT NOT NULL,
+    credits INT NOT NULL DEFAULT 0,
+    max_uses INT NOT NULL DEFAULT 1, -- how many players can redeem the voucher
+    uses INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP DEFAULT NULL, -- NULL for vouchers that never expire
+    created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    PRIMARY KEY (code)
+);
+
+-- Furniture given by a voucher, in addition to its credits.
+CREATE TABLE IF NOT EXISTS voucher_items (
+    id SERIAL,
+    code TEXT NOT NULL,
+    sprite TEXT NOT NULL,
+    FOREIGN KEY (code) REFERENCES vouchers(code) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    id SERIAL,
+    code TEXT NOT NULL,
+    player_id INT NOT NULL,
+    redeemed_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    FOREIGN KEY (code) REFERENCES vouchers(code) ON DELETE CASCADE,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    UNIQUE (code, player_id),
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS club_subscriptions (
+    player_id INT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    gifts_given INT NOT NULL DEFAULT 0,
+    next_gift_at TIMESTAMP NOT NULL, -- gifts are given once per club period, at the player's next login after this
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (player_id)
+);
+
+CREATE TABLE IF NOT EXISTS club_gifts (
+    id SERIAL,
+    player_id INT NOT NULL,
+    sprite TEXT NOT NULL,
+    given_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS club_gifts_player_idx ON club_gifts (player_id);
+
 CREATE TABLE IF NOT EXISTS room_categories (
     id SERIAL,
     parent_id INT NOT NULL,
@@ -54,8 +219,9 @@ CREATE TABLE IF NOT EXISTS room_categories (
     is_node BOOL NOT NULL DEFAULT false,
     is_public BOOL NOT NULL DEFAULT false,
     is_trading BOOL NOT NULL DEFAULT false,
-    min_rank_access INT NOT NULL DEFAULT 1,
-    min_rank_setflatcat INT NOT NULL DEFAULT 1,
+    access_right TEXT NOT NULL DEFAULT '', -- fuse right needed to see the category, everyone can if empty
+    setflatcat_right TEXT NOT NULL DEFAULT '', -- fuse right needed to put rooms in the category
+    club_only BOOL NOT NULL DEFAULT false, -- only Habbo Club members can browse the category & enter its rooms
     PRIMARY KEY (id)
 );
 
@@ -96,6 +262,20 @@ CREATE TABLE IF NOT EXISTS rooms (
     PRIMARY KEY (id)
 );
 
+CREATE INDEX IF NOT EXISTS rooms_owner_idx ON rooms (owner_id);
+CREATE INDEX IF NOT EXISTS rooms_category_idx ON rooms (category_id, current_visitors);
+CREATE INDEX IF NOT EXISTS rooms_name_idx ON rooms (LOWER(name) text_pattern_ops); -- room name searches
+CREATE INDEX IF NOT EXISTS rooms_visitors_idx ON rooms (current_visitors) WHERE current_visitors > 0;
+
+CREATE TABLE IF NOT EXISTS room_favourites (
+    player_id INT NOT NULL,
+    room_id INT NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    PRIMARY KEY (player_id, room_id)
+);
+
 INSERT INTO player_ranks (id, name)
 VALUES (0, 'No Rank'),
        (1, 'Normal'),
@@ -107,31 +287,68 @@ VALUES (0, 'No Rank'),
        (7, 'Administrator');
 
 
+INSERT INTO fuse_rights (fuse, min_rank, club_only)
+VALUES ('fuse_login', 1, false),
+       ('fuse_buy_credits', 1, false),
+       ('fuse_trade', 1, false),
+       ('fuse_room_queue_default', 1, false),
+       ('fuse_extended_buddylist', 1, true),
+       ('fuse_habbo_chooser', 1, true),
+       ('fuse_furni_chooser', 1, true),
+       ('fuse_room_queue_club', 1, true),
+       ('fuse_priority_access', 1, true),
+       ('fuse_use_special_room_layouts', 1, true),
+       ('fuse_use_club_dance', 1, true),
+       ('fuse_access_staff_categories', 4, false),
+       ('fuse_enter_full_rooms', 4, false),
+       ('fuse_alert', 4, false),
+       ('fuse_kick', 5, false),
+       ('fuse_see_all_roomowners', 5, false),
+       ('fuse_setflatcat_staff', 5, false),
+       ('fuse_moderator_access', 6, false),
+       ('fuse_ban', 6, false),
+       ('fuse_enter_locked_rooms', 6, false),
+       ('fuse_any_room_controller', 6, false),
+       ('fuse_see_flat_ids', 6, false),
+       ('fuse_setflatcat_public', 6, false),
+       ('fuse_extended_buddylist', 6, false),
+       ('fuse_habbo_chooser', 6, false),
+       ('fuse_furni_chooser', 6, false),
+       ('fuse_priority_access', 6, false),
+       ('fuse_use_club_dance', 6, false),
+       ('fuse_administrator_access', 7, false);
+
+INSERT INTO badges (code)
+VALUES ('ADM');
+
+INSERT INTO rank_badges (rank, badge_id)
+SELECT R.id, B.id FROM player_ranks R, badges B WHERE R.id IN (6, 7) AND B.code = 'ADM';
+
 INSERT INTO players (id, username, password_hash, password_salt, birthday, email)
 VALUES (0, 'Staff', '#WSECASDFAR$W', 'asdfashflskdjhfh', '2001-01-01', 'staff@habbgo.com');
 
-INSERT INTO room_categories (id, order_id, parent_id, is_node, name, is_public, is_trading, min_rank_access, min_rank_setflatcat)
-VALUES (2, 0, 0, false, 'No category', false, false, 1, 1),
-       (3, 0, 0, true, 'Public Rooms', true, false, 1, 6),
-       (4, 0, 0, true, 'Guest Rooms', false, false, 1, 6),
-       (5, 0, 3, false, 'Entertainment', true, false, 1, 6),
-       (6, 0, 3, false, 'Restaurants and Cafes', true, false, 1, 6),
-       (7, 0, 3, false, 'Lounges and Clubs', true, false, 1, 6),
-       (8, 0, 3, false, 'Club-only Spaces', true, false, 1, 6),
-       (9, 0, 3, false, 'Parks and Gardens', true, false, 1, 6),
-       (10, 0, 3, false, 'Swimming Pools', true, false, 1, 6),
-       (11, 0, 3, false, 'The Lobbies', true, false, 1, 6),
-       (12, -1, 3, false, 'The Hallways', true, false, 1, 6),
-       (13, 0, 3, false, 'Games', true, false, 1, 6),
-       (101, 0, 4, false, 'Staff HQ', false, true, 4, 5),
-       (112, 0, 4, false, 'Restaurant, Bar & Night Club Rooms', false, false, 1, 1),
-       (113, 0, 4, false, 'Trade floor', false, true, 1, 1),
-       (114, 0, 4, false, 'Chill, Chat & Discussion Rooms', false, false, 1, 1),
-       (115, 0, 4, false, 'Hair Salons & Modelling Rooms', false, false, 1, 1),
-       (116, 0, 4, false, 'Maze & Theme Park Rooms', false, false, 1, 1),
-       (117, 0, 4, false, 'Gaming & Race Rooms', false, false, 1, 1),
-       (118, 0, 4, false, 'Help Centre Rooms', false, false, 1, 1),
-       (120, 0, 4, false, 'Miscellaneous', false, false, 1, 1);
+INSERT INTO room_categories (id, order_id, parent_id, is_node, name, is_public, is_trading, access_right, setflatcat_right, club_only)
+VALUES (2, 0, 0, false, 'No category', false, false, '', '', false),
+       (3, 0, 0, true, 'Public Rooms', true, false, '', 'fuse_setflatcat_public', false),
+       (4, 0, 0, true, 'Guest Rooms', false, false, '', 'fuse_setflatcat_public', false),
+       (5, 0, 3, false, 'Entertainment', true, false, '', 'fuse_setflatcat_public', false),
+       (6, 0, 3, false, 'Restaurants and Cafes', true, false, '', 'fuse_setflatcat_public', false),
+       (7, 0, 3, false, 'Lounges and Clubs', true, false, '', 'fuse_setflatcat_public', false),
+       (8, 0, 3, false, 'Club-only Spaces', true, false, '', 'fuse_setflatcat_public', true),
+       (9, 0, 3, false, 'Parks and Gardens', true, false, '', 'fuse_setflatcat_public', false),
+       (10, 0, 3, false, 'Swimming Pools', true, false, '', 'fuse_setflatcat_public', false),
+       (11, 0, 3, false, 'The Lobbies', true, false, '', 'fuse_setflatcat_public', false),
+       (12, -1, 3, false, 'The Hallways', true, false, '', 'fuse_setflatcat_public', false),
+       (13, 0, 3, false, 'Games', true, false, '', 'fuse_setflatcat_public', false),
+       (101, 0, 4, false, 'Staff HQ', false, true, 'fuse_access_staff_categories', 'fuse_setflatcat_staff', false),
+       (112, 0, 4, false, 'Restaurant, Bar & Night Club Rooms', false, false, '', '', false),
+       (113, 0, 4, false, 'Trade floor', false, true, '', '', false),
+       (114, 0, 4, false, 'Chill, Chat & Discussion Rooms', false, false, '', '', false),
+       (115, 0, 4, false, 'Hair Salons & Modelling Rooms', false, false, '', '', false),
+       (116, 0, 4, false, 'Maze & Theme Park Rooms', false, false, '', '', false),
+       (117, 0, 4, false, 'Gaming & Race Rooms', false, false, '', '', false),
+       (118, 0, 4, false, 'Help Centre Rooms', false, false, '', '', false),
+       (120, 0, 4, false, 'Miscellaneous', false, false, '', '', false);
 
 INSERT INTO room_models (id, name, door_x, door_y, door_z, door_dir, heightmap) VALUES
     (1, 'model_a', 3, 5, 0, 2, 'xxxxxxxxxxxx|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxx00000000|xxxxxxxxxxxx|xxxxxxxxxxxx'),
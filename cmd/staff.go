@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jtieri/habbgo/game/badge"
+	"github.com/jtieri/habbgo/game/ban"
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// loginHistoryLength is the number of logins listed by staff logins.
+const loginHistoryLength = 10
+
+var staffFlags struct {
+	database string
+	ip       string
+	machine  string
+}
+
+var staffCmd = &cobra.Command{
+	Use:   "staff",
+	Short: "Moderation tasks the client's hobba tool has no action for",
+}
+
+var staffUnbanCmd = &cobra.Command{
+	Use:   "unban [username] [--ip <addr>] [--machine <id>]",
+	Short: "Lift every active ban on a player's account, an IP address or a machine ID",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && staffFlags.ip == "" && staffFlags.machine == "" {
+			return errors.New("give a username, --ip or --machine")
+		}
+
+		var (
+			db       *sql.DB
+			playerId int
+			err      error
+		)
+		if len(args) == 1 {
+			db, playerId, err = staffTarget(args[0])
+		} else {
+			db, err = staffDatabase()
+		}
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		bans := ban.NewBanService(zap.NewNop(), db)
+		lift := func(banType ban.Type, value, name string) error {
+			n, err := bans.Lift(banType, value)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Lifted %d %s ban(s) on %s.\n", n, banType, name)
+			return nil
+		}
+
+		if len(args) == 1 {
+			if err := lift(ban.Account, strconv.Itoa(playerId), args[0]); err != nil {
+				return err
+			}
+		}
+		if staffFlags.ip != "" {
+			if err := lift(ban.IP, staffFlags.ip, staffFlags.ip); err != nil {
+				return err
+			}
+		}
+		if staffFlags.machine != "" {
+			if err := lift(ban.Machine, staffFlags.machine, staffFlags.machine); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var staffAltsCmd = &cobra.Command{
+	Use:   "alts <username>",
+	Short: "List the accounts that logged in from the same machine IDs or IP addresses as a player",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, playerId, err := staffTarget(args[0])
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		logins, err := player.NewLoginRepo(db).LinkedAccounts(playerId)
+		if err != nil {
+			return err
+		}
+		for _, l := range logins {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", l.Username, l.MachineId, l.Address,
+				l.LoggedInAt.Format("02-01-2006"))
+		}
+		return nil
+	},
+}
+
+var staffLoginsCmd = &cobra.Command{
+	Use:   "logins <username>",
+	Short: "List a player's most recent logins and their total online time",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, playerId, err := staffTarget(args[0])
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		repo := player.NewLoginRepo(db)
+		logins, err := repo.History(playerId, loginHistoryLength)
+		if err != nil {
+			return err
+		}
+		online, err := repo.OnlineTime(playerId)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Online for %s in total.\n", online.Round(time.Minute))
+		for _, l := range logins {
+			session := "no logout recorded"
+			if l.LoggedOutAt.Valid {
+				session = l.Duration().Round(time.Second).String()
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", l.LoggedInAt.Format("02-01-2006 15:04"), l.MachineId,
+				l.Address, session)
+		}
+		return nil
+	},
+}
+
+var staffBadgeCmd = &cobra.Command{
+	Use:   "badge grant|revoke <username> <code>",
+	Short: "Give a player a badge or take one from them, online players see the change when they next log in",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		grant := args[0] == "grant"
+		if !grant && args[0] != "revoke" {
+			return fmt.Errorf("unknown badge action %q, use grant or revoke", args[0])
+		}
+
+		db, playerId, err := staffTarget(args[1])
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		badges := badge.NewBadgeService(zap.NewNop(), db)
+		if err := badges.Reload(); err != nil {
+			return err
+		}
+
+		if grant {
+			err = badges.Grant(playerId, args[2])
+		} else {
+			err = badges.Revoke(playerId, args[2])
+		}
+		switch {
+		case err == badge.ErrUnknownBadge:
+			return fmt.Errorf("badge %s does not exist", args[2])
+		case err != nil:
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "The badges of %s have been updated.\n", args[1])
+		return nil
+	},
+}
+
+// staffDatabase connects to the database given with --db.
+func staffDatabase() (*sql.DB, error) {
+	if staffFlags.database == "" {
+		return nil, errors.New("no database given, set --db or HABBGO_DATABASE_URL")
+	}
+	return sql.Open("postgres", staffFlags.database)
+}
+
+// staffTarget connects to the database given with --db and looks up the id of the player with the given username.
+func staffTarget(username string) (*sql.DB, int, error) {
+	db, err := staffDatabase()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	playerId, err := player.PlayerIdByName(db, username)
+	if err != nil {
+		db.Close()
+		if err == player.ErrPlayerNotFound {
+			return nil, 0, fmt.Errorf("player %s does not exist", username)
+		}
+		return nil, 0, err
+	}
+	return db, playerId, nil
+}
+
+func init() {
+	staffCmd.PersistentFlags().StringVar(&staffFlags.database, "db", os.Getenv("HABBGO_DATABASE_URL"),
+		"postgres connection string of the game database")
+
+	staffUnbanCmd.Flags().StringVar(&staffFlags.ip, "ip", "", "IP address to lift bans on")
+	staffUnbanCmd.Flags().StringVar(&staffFlags.machine, "machine", "", "machine ID to lift bans on")
+
+	staffCmd.AddCommand(staffUnbanCmd, staffAltsCmd, staffLoginsCmd, staffBadgeCmd)
+	rootCmd.AddCommand(staffCmd)
+}
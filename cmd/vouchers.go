@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jtieri/habbgo/game/voucher"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var voucherFlags struct {
+	database string
+	count    int
+	credits  int
+	items    string
+	uses     int
+	expires  time.Duration
+}
+
+var vouchersCmd = &cobra.Command{
+	Use:   "vouchers",
+	Short: "Manage the vouchers players redeem in the purse",
+}
+
+var vouchersGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a batch of voucher codes and print them, one per line",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var items []string
+		for _, item := range strings.Split(voucherFlags.items, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		var expiresAt *time.Time
+		if voucherFlags.expires > 0 {
+			t := time.Now().UTC().Add(voucherFlags.expires)
+			expiresAt = &t
+		}
+
+		vs, db, err := voucherService()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		codes, err := vs.Generate(voucherFlags.count, voucherFlags.credits, items, voucherFlags.uses, expiresAt)
+		if err != nil {
+			return err
+		}
+		for _, code := range codes {
+			fmt.Fprintln(cmd.OutOrStdout(), code)
+		}
+		return nil
+	},
+}
+
+var vouchersHistoryCmd = &cobra.Command{
+	Use:   "history <code>",
+	Short: "List the players who redeemed a voucher",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vs, db, err := voucherService()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		redemptions, err := vs.Redemptions(args[0])
+		if err != nil {
+			return err
+		}
+		for _, r := range redemptions {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\tplayer %d\t%s\n", r.Code, r.PlayerId, r.RedeemedAt.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+// voucherService connects to the database given with --db and returns a voucher.VoucherService using it.
+func voucherService() (*voucher.VoucherService, *sql.DB, error) {
+	if voucherFlags.database == "" {
+		return nil, nil, errors.New("no database given, set --db or HABBGO_DATABASE_URL")
+	}
+
+	db, err := sql.Open("postgres", voucherFlags.database)
+	if err != nil {
+		return nil, nil, err
+	}
+	return voucher.NewVoucherService(zap.NewNop(), db), db, nil
+}
+
+func init() {
+	vouchersCmd.PersistentFlags().StringVar(&voucherFlags.database, "db", os.Getenv("HABBGO_DATABASE_URL"),
+		"postgres connection string of the game database")
+
+	flags := vouchersGenerateCmd.Flags()
+	flags.IntVar(&voucherFlags.count, "count", 1, "number of vouchers to generate")
+	flags.IntVar(&voucherFlags.credits, "credits", 0, "credits each voucher gives")
+	flags.StringVar(&voucherFlags.items, "items", "", "comma separated sprites of the furniture each voucher gives, not supported until players have inventories")
+	flags.IntVar(&voucherFlags.uses, "uses", 1, "number of players that can redeem each voucher")
+	flags.DurationVar(&voucherFlags.expires, "expires", 0, "how long the vouchers can be redeemed for, forever if 0")
+
+	vouchersCmd.AddCommand(vouchersGenerateCmd, vouchersHistoryCmd)
+	rootCmd.AddCommand(vouchersCmd)
+}
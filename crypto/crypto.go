@@ -3,10 +3,15 @@ package crypto
 import (
 	"crypto/rand"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 )
 
-const SALTSIZE = 16 // default password salt size in bytes
+const (
+	SALTSIZE  = 16 // default password salt size in bytes
+	TOKENSIZE = 24 // default size in bytes of tokens handed out for SSO tickets, password resets etc.
+)
 
 // GenerateRandomSalt creates a new slice of bytes and generates a random salt using the cryptographically secure CSPRNG
 func GenerateRandomSalt(saltSize int) []byte {
@@ -17,8 +22,15 @@ func GenerateRandomSalt(saltSize int) []byte {
 	return salt
 }
 
+// GenerateToken returns a hex encoded string of tokenSize random bytes generated using the CSPRNG,
+// suitable for single-use tickets and confirmation links.
+func GenerateToken(tokenSize int) string {
+	return hex.EncodeToString(GenerateRandomSalt(tokenSize))
+}
+
 // HashPassword will concatenate the provided password and salt, then use SHA-512 to hash the password
-// before returning it as a base64 encoded string
+// before returning it as a base64 encoded string.
+// Deprecated: only kept to verify legacy hashes, new hashes are made with a PasswordHasher.
 func HashPassword(password string, salt []byte) string {
 	var sha512Hasher = sha512.New() // Create a sha-512 hasher
 
@@ -32,7 +44,8 @@ func HashPassword(password string, salt []byte) string {
 	return base64.URLEncoding.EncodeToString(hashedPasswordBytes)
 }
 
-// PasswordsMatch ensures an un-hashed string matches a hashed string once it is combined with a salt & hashed itself
+// PasswordsMatch ensures an un-hashed string matches a hashed string once it is combined with a salt & hashed itself.
+// The hashes are compared in constant time.
 func PasswordsMatch(hashedPassword, unhashedPass string, salt []byte) bool {
-	return hashedPassword == HashPassword(unhashedPass, salt)
+	return subtle.ConstantTimeCompare([]byte(hashedPassword), []byte(HashPassword(unhashedPass, salt))) == 1
 }
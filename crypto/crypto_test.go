@@ -2,6 +2,8 @@ package crypto
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 // TestLoginHash tests that the password hash/salt functions are working as intended
@@ -23,3 +25,56 @@ func TestLoginHash(t *testing.T) {
 
 	t.Log("Password Match:", PasswordsMatch(hashedPassword, "hello", salt))
 }
+
+// testArgon2id uses cheap parameters so the tests run quickly.
+func testArgon2id() *Argon2id {
+	return &Argon2id{Time: 1, Memory: 1024, Threads: 1, SaltLen: SALTSIZE, KeyLen: 32}
+}
+
+func TestPasswordHashers(t *testing.T) {
+	for _, hasher := range []PasswordHasher{testArgon2id(), &Bcrypt{Cost: 4}} {
+		encoded, err := hasher.Hash("treebeard1")
+		require.NoError(t, err)
+		require.True(t, hasher.Handles(encoded))
+		require.False(t, hasher.NeedsRehash(encoded))
+
+		ok, err := hasher.Verify(encoded, "treebeard1")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = hasher.Verify(encoded, "treebeard2")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	encoded, err := testArgon2id().Hash("treebeard1")
+	require.NoError(t, err)
+	require.True(t, DefaultArgon2id().NeedsRehash(encoded))
+
+	_, err = NewPasswordHasher("md5")
+	require.Error(t, err)
+}
+
+func TestVerifyPasswordMigratesLegacyHashes(t *testing.T) {
+	hasher := testArgon2id()
+	salt := GenerateRandomSalt(SALTSIZE)
+	legacy := HashPassword("treebeard1", salt)
+
+	match, rehash := VerifyPassword(hasher, legacy, salt, "treebeard1")
+	require.True(t, match)
+	require.True(t, rehash)
+
+	match, _ = VerifyPassword(hasher, legacy, salt, "treebeard2")
+	require.False(t, match)
+
+	encoded, err := hasher.Hash("treebeard1")
+	require.NoError(t, err)
+	match, rehash = VerifyPassword(hasher, encoded, nil, "treebeard1")
+	require.True(t, match)
+	require.False(t, rehash)
+
+	// Switching hashers still accepts the old hashes but migrates them.
+	match, rehash = VerifyPassword(&Bcrypt{Cost: 4}, encoded, nil, "treebeard1")
+	require.True(t, match)
+	require.True(t, rehash)
+}
@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// PasswordHasher hashes passwords into a self-describing encoded string, carrying the algorithm, its parameters
+// and the salt, so that stored hashes can be verified even after the hasher's parameters change.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches the encoded hash.
+	Verify(encoded, password string) (bool, error)
+	// Handles reports whether the encoded hash was produced by this kind of hasher.
+	Handles(encoded string) bool
+	// NeedsRehash reports whether the encoded hash was produced with different parameters than the hasher's.
+	NeedsRehash(encoded string) bool
+}
+
+// NewPasswordHasher returns the PasswordHasher with the given name, either argon2id or bcrypt, using its default
+// parameters.
+func NewPasswordHasher(name string) (PasswordHasher, error) {
+	switch strings.ToLower(name) {
+	case "", "argon2id":
+		return DefaultArgon2id(), nil
+	case "bcrypt":
+		return DefaultBcrypt(), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hasher %q", name)
+	}
+}
+
+// VerifyPassword checks password against a stored hash and reports whether it matched and whether the stored hash
+// should be replaced with a fresh one from hasher. Hashes made by any supported hasher are accepted, as are legacy
+// salted SHA-512 hashes, which are always flagged for rehashing so that accounts migrate on their next login.
+func VerifyPassword(hasher PasswordHasher, encoded string, salt []byte, password string) (match bool, rehash bool) {
+	for _, h := range []PasswordHasher{hasher, DefaultArgon2id(), DefaultBcrypt()} {
+		if !h.Handles(encoded) {
+			continue
+		}
+
+		ok, err := h.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false
+		}
+		return true, !hasher.Handles(encoded) || hasher.NeedsRehash(encoded)
+	}
+
+	// Anything else is treated as a legacy single round SHA-512 hash of the password & salt.
+	return PasswordsMatch(encoded, password, salt), true
+}
+
+// Argon2id is a PasswordHasher using argon2id, encoded in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+type Argon2id struct {
+	Time    uint32 // number of passes over the memory
+	Memory  uint32 // memory size in KiB
+	Threads uint8
+	SaltLen uint32 // salt size in bytes
+	KeyLen  uint32 // hash size in bytes
+}
+
+// DefaultArgon2id returns an Argon2id hasher using 64 MiB of memory over 3 passes.
+func DefaultArgon2id() *Argon2id {
+	return &Argon2id{Time: 3, Memory: 64 * 1024, Threads: 2, SaltLen: SALTSIZE, KeyLen: 32}
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := GenerateRandomSalt(int(a.SaltLen))
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a *Argon2id) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.Time != a.Time || params.Memory != a.Memory || params.Threads != a.Threads ||
+		uint32(len(salt)) != a.SaltLen || uint32(len(key)) != a.KeyLen
+}
+
+// decodeArgon2id splits a PHC formatted argon2id hash into its parameters, salt and key.
+func decodeArgon2id(encoded string) (params *Argon2id, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	params = &Argon2id{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	return params, salt, key, nil
+}
+
+// Bcrypt is a PasswordHasher using bcrypt, which carries its cost and salt in the modular crypt format.
+type Bcrypt struct {
+	Cost int
+}
+
+// DefaultBcrypt returns a Bcrypt hasher with a cost of 12.
+func DefaultBcrypt() *Bcrypt {
+	return &Bcrypt{Cost: 12}
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b *Bcrypt) Verify(encoded, password string) (bool, error) {
+	// CompareHashAndPassword compares in constant time.
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	switch err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (b *Bcrypt) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.Cost
+}
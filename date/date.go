@@ -19,3 +19,12 @@ func GetCurrentDate() string {
 	formatted := fmt.Sprintf("%02d-%02d-%d", t.Day(), t.Month(), t.Year())
 	return formatted
 }
+
+// Age returns how many full years old someone born on birthday is at now.
+func Age(birthday, now time.Time) int {
+	age := now.Year() - birthday.Year()
+	if now.Month() < birthday.Month() || (now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
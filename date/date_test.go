@@ -2,9 +2,24 @@ package date
 
 import (
 	"testing"
+	"time"
 )
 
 func TestGetCurrentDate(t *testing.T) {
 	t.Log(GetCurrentDate())
 	t.Log(GetCurrentDateTime())
 }
+
+func TestAge(t *testing.T) {
+	birthday := time.Date(2008, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	if age := Age(birthday, time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)); age != 12 {
+		t.Errorf("expected age 12 the day before the birthday, got %d", age)
+	}
+	if age := Age(birthday, time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)); age != 13 {
+		t.Errorf("expected age 13 on the birthday, got %d", age)
+	}
+	if age := Age(birthday, time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)); age != 13 {
+		t.Errorf("expected age 13 after the birthday, got %d", age)
+	}
+}
@@ -0,0 +1,120 @@
+package badge
+
+import (
+	"database/sql"
+
+	"github.com/jtieri/habbgo/game/ranks"
+)
+
+type BadgeRepo struct {
+	database *sql.DB
+}
+
+// NewBadgeRepo returns a new instance of BadgeRepo.
+func NewBadgeRepo(db *sql.DB) *BadgeRepo {
+	return &BadgeRepo{database: db}
+}
+
+// Catalogue returns every badge.
+func (br *BadgeRepo) Catalogue() ([]Badge, error) {
+	rows, err := br.database.Query("SELECT B.id, B.code FROM badges B ORDER BY B.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var badges []Badge
+	for rows.Next() {
+		var b Badge
+		if err := rows.Scan(&b.Id, &b.Code); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+
+	return badges, rows.Err()
+}
+
+// RankBadges returns the IDs of the badges every player of a rank owns, keyed by rank.
+func (br *BadgeRepo) RankBadges() (map[ranks.Rank][]int, error) {
+	rows, err := br.database.Query("SELECT R.rank, R.badge_id FROM rank_badges R ORDER BY R.rank, R.badge_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rankBadges := make(map[ranks.Rank][]int)
+	for rows.Next() {
+		var (
+			rank    int
+			badgeId int
+		)
+		if err := rows.Scan(&rank, &badgeId); err != nil {
+			return nil, err
+		}
+		rankBadges[ranks.Rank(rank)] = append(rankBadges[ranks.Rank(rank)], badgeId)
+	}
+
+	return rankBadges, rows.Err()
+}
+
+// PlayerBadges returns the IDs of the badges given to the player.
+func (br *BadgeRepo) PlayerBadges(playerId int) ([]int, error) {
+	rows, err := br.database.Query(
+		"SELECT P.badge_id FROM player_badges P WHERE P.player_id = $1 ORDER BY P.badge_id", playerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Grant gives the player a badge, doing nothing if they already have it.
+func (br *BadgeRepo) Grant(playerId, badgeId int) error {
+	_, err := br.database.Exec(
+		"INSERT INTO player_badges(player_id, badge_id) VALUES($1, $2) ON CONFLICT DO NOTHING", playerId, badgeId)
+	return err
+}
+
+// Revoke takes a badge from the player, clearing their selection if they were wearing it.
+func (br *BadgeRepo) Revoke(playerId int, badge Badge) error {
+	tx, err := br.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM player_badges WHERE player_id = $1 AND badge_id = $2", playerId, badge.Id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE players SET badge = '' WHERE id = $1 AND badge = $2", playerId, badge.Code); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Selection returns the badge the player has chosen to wear.
+func (br *BadgeRepo) Selection(playerId int) (Selection, error) {
+	var s Selection
+	err := br.database.QueryRow("SELECT P.badge, P.badge_visible FROM players P WHERE P.id = $1", playerId).
+		Scan(&s.Code, &s.Visible)
+	return s, err
+}
+
+// SetSelection stores the badge the player has chosen to wear.
+func (br *BadgeRepo) SetSelection(playerId int, s Selection) error {
+	_, err := br.database.Exec("UPDATE players SET badge = $1, badge_visible = $2 WHERE id = $3",
+		s.Code, s.Visible, playerId)
+	return err
+}
@@ -0,0 +1,150 @@
+package badge
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/jtieri/habbgo/game/ranks"
+	"go.uber.org/zap"
+)
+
+// BadgeService caches the badge catalogue and manages which badges players own & wear.
+// Badges implied by a player's rank aren't stored per player, they're added whenever the player's badges are listed.
+type BadgeService struct {
+	repo *BadgeRepo
+
+	mux    sync.RWMutex
+	byId   map[int]Badge
+	byCode map[string]Badge
+	ranks  map[ranks.Rank][]int
+
+	log *zap.Logger
+}
+
+func NewBadgeService(log *zap.Logger, db *sql.DB) *BadgeService {
+	return &BadgeService{
+		repo:   NewBadgeRepo(db),
+		byId:   make(map[int]Badge),
+		byCode: make(map[string]Badge),
+		ranks:  make(map[ranks.Rank][]int),
+		log:    log,
+	}
+}
+
+// Build loads the badge catalogue & rank badges into the cache.
+func (bs *BadgeService) Build() {
+	if err := bs.Reload(); err != nil {
+		bs.log.Warn("Failed to load the badge catalogue", zap.Error(err))
+	}
+}
+
+// Reload replaces the cached catalogue & rank badges with the ones in the database.
+func (bs *BadgeService) Reload() error {
+	catalogue, err := bs.repo.Catalogue()
+	if err != nil {
+		return err
+	}
+	rankBadges, err := bs.repo.RankBadges()
+	if err != nil {
+		return err
+	}
+
+	byId := make(map[int]Badge, len(catalogue))
+	byCode := make(map[string]Badge, len(catalogue))
+	for _, b := range catalogue {
+		byId[b.Id] = b
+		byCode[b.Code] = b
+	}
+
+	bs.mux.Lock()
+	defer bs.mux.Unlock()
+	bs.byId, bs.byCode, bs.ranks = byId, byCode, rankBadges
+	return nil
+}
+
+// BadgeByCode returns the badge with the given code from the catalogue.
+func (bs *BadgeService) BadgeByCode(code string) (Badge, bool) {
+	bs.mux.RLock()
+	defer bs.mux.RUnlock()
+	b, ok := bs.byCode[code]
+	return b, ok
+}
+
+// Badges returns the codes of every badge the player owns, those implied by their rank first.
+func (bs *BadgeService) Badges(playerId int, rank ranks.Rank) ([]string, error) {
+	owned, err := bs.repo.PlayerBadges(playerId)
+	if err != nil {
+		return nil, err
+	}
+
+	bs.mux.RLock()
+	defer bs.mux.RUnlock()
+
+	seen := make(map[int]bool)
+	var codes []string
+	for _, id := range append(append([]int{}, bs.ranks[rank]...), owned...) {
+		b, ok := bs.byId[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		codes = append(codes, b.Code)
+	}
+
+	return codes, nil
+}
+
+// Owns reports whether the player owns the badge with the given code, either directly or through their rank.
+func (bs *BadgeService) Owns(playerId int, rank ranks.Rank, code string) (bool, error) {
+	codes, err := bs.Badges(playerId, rank)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Grant gives the player the badge with the given code.
+func (bs *BadgeService) Grant(playerId int, code string) error {
+	b, ok := bs.BadgeByCode(code)
+	if !ok {
+		return ErrUnknownBadge
+	}
+	return bs.repo.Grant(playerId, b.Id)
+}
+
+// Revoke takes the badge with the given code from the player. Badges implied by the player's rank can't be revoked
+// without changing their rank.
+func (bs *BadgeService) Revoke(playerId int, code string) error {
+	b, ok := bs.BadgeByCode(code)
+	if !ok {
+		return ErrUnknownBadge
+	}
+	return bs.repo.Revoke(playerId, b)
+}
+
+// Selection returns the badge the player has chosen to wear.
+func (bs *BadgeService) Selection(playerId int) (Selection, error) {
+	return bs.repo.Selection(playerId)
+}
+
+// Select makes the player wear the badge with the given code, as long as they own it. An empty code takes off the
+// badge they're wearing.
+func (bs *BadgeService) Select(playerId int, rank ranks.Rank, s Selection) error {
+	if s.Code != "" {
+		owns, err := bs.Owns(playerId, rank, s.Code)
+		if err != nil {
+			return err
+		}
+		if !owns {
+			return ErrNotOwned
+		}
+	}
+
+	return bs.repo.SetSelection(playerId, s)
+}
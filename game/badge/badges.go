@@ -1,10 +1,24 @@
 package badge
 
+import "errors"
+
+var (
+	ErrUnknownBadge = errors.New("badge does not exist")
+	ErrNotOwned     = errors.New("player does not own badge")
+)
+
+// Badge is a badge from the catalogue that players can be given and wear.
 type Badge struct {
-	Id   uint
-	code string
+	Id   int
+	Code string
 }
 
 func (b Badge) String() string {
-	return b.code
+	return b.Code
+}
+
+// Selection is the badge a player has chosen to wear and whether it's shown to other players.
+type Selection struct {
+	Code    string // empty if no badge has been chosen
+	Visible bool
 }
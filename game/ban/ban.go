@@ -0,0 +1,63 @@
+package ban
+
+import (
+	"fmt"
+	"time"
+)
+
+type Ban struct {
+	ID        int
+	Type      Type
+	Value     string // player id, IP address or machine ID depending on Type
+	Reason    string
+	BannedBy  int
+	BannedAt  time.Time
+	ExpiresAt *time.Time // nil for permanent bans
+}
+
+// Active reports whether the ban is still in effect.
+func (b *Ban) Active() bool {
+	return b.ExpiresAt == nil || time.Now().UTC().Before(*b.ExpiresAt)
+}
+
+// Message returns the text shown to a banned player, their ban reason along with when the ban expires.
+func (b *Ban) Message() string {
+	if b.ExpiresAt == nil {
+		return fmt.Sprintf("%s\r\rThis ban is permanent.", b.Reason)
+	}
+	return fmt.Sprintf("%s\r\rThis ban expires on %s.", b.Reason, b.ExpiresAt.Format("02-01-2006 15:04"))
+}
+
+type Type int
+
+const (
+	Account Type = iota
+	IP
+	Machine
+)
+
+func (t Type) String() string {
+	switch t {
+	case Account:
+		return "account"
+	case IP:
+		return "ip"
+	case Machine:
+		return "machine"
+	default:
+		return "account"
+	}
+}
+
+func BanType(typeString string) Type {
+	switch typeString {
+	case "account":
+		return Account
+	case "ip":
+		return IP
+	case "machine":
+		return Machine
+	default:
+		return Account
+	}
+}
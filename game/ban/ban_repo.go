@@ -0,0 +1,72 @@
+package ban
+
+import (
+	"database/sql"
+	"time"
+)
+
+type BanRepo struct {
+	database *sql.DB
+}
+
+// NewBanRepo returns a new instance of BanRepo for use in the ban service.
+func NewBanRepo(db *sql.DB) *BanRepo {
+	return &BanRepo{database: db}
+}
+
+// ActiveBan returns the longest running, unlifted & unexpired ban of the given type on value,
+// or nil if there isn't one.
+func (br *BanRepo) ActiveBan(banType Type, value string) (*Ban, error) {
+	b := &Ban{}
+
+	var (
+		tmpType   string
+		bannedBy  sql.NullInt64
+		expiresAt sql.NullTime
+	)
+
+	err := br.database.QueryRow(
+		"SELECT B.id, B.ban_type, B.value, B.reason, B.banned_by, B.banned_at, B.expires_at FROM bans B "+
+			"WHERE B.ban_type = $1 AND B.value = $2 AND NOT B.lifted AND (B.expires_at IS NULL OR B.expires_at > $3) "+
+			"ORDER BY B.expires_at DESC NULLS FIRST LIMIT 1", banType.String(), value, time.Now().UTC()).
+		Scan(&b.ID, &tmpType, &b.Value, &b.Reason, &bannedBy, &b.BannedAt, &expiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	b.Type = BanType(tmpType)
+	b.BannedBy = int(bannedBy.Int64)
+	if expiresAt.Valid {
+		b.ExpiresAt = &expiresAt.Time
+	}
+
+	return b, nil
+}
+
+// AddBan stores a new ban and sets its ID.
+func (br *BanRepo) AddBan(b *Ban) error {
+	var bannedBy sql.NullInt64
+	if b.BannedBy != 0 {
+		bannedBy = sql.NullInt64{Int64: int64(b.BannedBy), Valid: true}
+	}
+
+	return br.database.QueryRow(
+		"INSERT INTO bans(ban_type, value, reason, banned_by, banned_at, expires_at) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
+		b.Type.String(), b.Value, b.Reason, bannedBy, b.BannedAt, b.ExpiresAt).
+		Scan(&b.ID)
+}
+
+// LiftBans lifts every active ban of the given type on value and returns how many were lifted.
+func (br *BanRepo) LiftBans(banType Type, value string) (int64, error) {
+	res, err := br.database.Exec("UPDATE bans SET lifted = true WHERE ban_type = $1 AND value = $2 AND NOT lifted",
+		banType.String(), value)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,123 @@
+package ban
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type BanService struct {
+	repo *BanRepo
+	log  *zap.Logger
+}
+
+func NewBanService(log *zap.Logger, db *sql.DB) *BanService {
+	return &BanService{
+		repo: NewBanRepo(db),
+		log:  log,
+	}
+}
+
+func (bs *BanService) Build() {
+
+}
+
+// Check returns the first active ban found on the player's account, IP address or machine ID, or nil if none of
+// them are banned. Any of the values can be left empty, e.g. before a player has logged in.
+func (bs *BanService) Check(playerId int, ip, machineId string) *Ban {
+	checks := []struct {
+		banType Type
+		value   string
+	}{
+		{Account, accountValue(playerId)},
+		{IP, ip},
+		{Machine, machineId},
+	}
+
+	for _, c := range checks {
+		if c.value == "" {
+			continue
+		}
+
+		b, err := bs.repo.ActiveBan(c.banType, c.value)
+		if err != nil {
+			bs.log.Warn("Failed to check for ban",
+				zap.String("ban_type", c.banType.String()),
+				zap.String("value", c.value),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if b != nil {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// Ban bans value, a player id, IP address or machine ID depending on banType, for the given duration.
+// A duration of zero bans permanently.
+func (bs *BanService) Ban(banType Type, value, reason string, bannedBy int, duration time.Duration) (*Ban, error) {
+	now := time.Now().UTC()
+	b := &Ban{
+		Type:     banType,
+		Value:    value,
+		Reason:   reason,
+		BannedBy: bannedBy,
+		BannedAt: now,
+	}
+
+	if duration > 0 {
+		expiresAt := now.Add(duration)
+		b.ExpiresAt = &expiresAt
+	}
+
+	if err := bs.repo.AddBan(b); err != nil {
+		return nil, err
+	}
+
+	bs.log.Info("Ban issued",
+		zap.String("ban_type", banType.String()),
+		zap.String("value", value),
+		zap.Int("banned_by", bannedBy),
+		zap.Duration("duration", duration),
+	)
+	return b, nil
+}
+
+// BanAccount bans the player with the given id, see Ban.
+func (bs *BanService) BanAccount(playerId int, reason string, bannedBy int, duration time.Duration) (*Ban, error) {
+	return bs.Ban(Account, accountValue(playerId), reason, bannedBy, duration)
+}
+
+// Lift lifts every active ban of the given type on value and returns how many bans were lifted.
+func (bs *BanService) Lift(banType Type, value string) (int64, error) {
+	n, err := bs.repo.LiftBans(banType, value)
+	if err != nil {
+		return 0, err
+	}
+
+	bs.log.Info("Bans lifted",
+		zap.String("ban_type", banType.String()),
+		zap.String("value", value),
+		zap.Int64("num_lifted", n),
+	)
+	return n, nil
+}
+
+// LiftAccount lifts every active ban on the player with the given id, see Lift.
+func (bs *BanService) LiftAccount(playerId int) (int64, error) {
+	return bs.Lift(Account, accountValue(playerId))
+}
+
+// accountValue returns the value account bans are stored under for the player with the given id.
+func accountValue(playerId int) string {
+	if playerId == 0 {
+		return ""
+	}
+	return strconv.Itoa(playerId)
+}
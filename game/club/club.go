@@ -0,0 +1,78 @@
+package club
+
+import (
+	"errors"
+	"time"
+)
+
+// PeriodLength is the length of a club month, memberships are bought & gifts are given per period.
+const PeriodLength = 31 * 24 * time.Hour
+
+var ErrUnknownOption = errors.New("unknown club purchase option")
+
+// Subscription is a player's Habbo Club membership.
+type Subscription struct {
+	PlayerId   int
+	StartedAt  time.Time
+	ExpiresAt  time.Time
+	NextGiftAt time.Time
+	GiftsGiven int
+}
+
+// Active reports whether the membership hasn't expired at now.
+func (s *Subscription) Active(now time.Time) bool {
+	return s != nil && now.Before(s.ExpiresAt)
+}
+
+// DaysLeft returns how many days are left of the current period, counting the current day.
+func (s *Subscription) DaysLeft(now time.Time) int {
+	if !s.Active(now) {
+		return 0
+	}
+
+	left := s.ExpiresAt.Sub(now) % PeriodLength
+	if left == 0 {
+		left = PeriodLength
+	}
+	return int((left + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// ElapsedPeriods returns how many whole periods have passed since the membership started.
+func (s *Subscription) ElapsedPeriods(now time.Time) int {
+	if s == nil || now.Before(s.StartedAt) {
+		return 0
+	}
+	return int(now.Sub(s.StartedAt) / PeriodLength)
+}
+
+// PrepaidPeriods returns how many whole periods are paid for after the current one.
+func (s *Subscription) PrepaidPeriods(now time.Time) int {
+	if !s.Active(now) {
+		return 0
+	}
+	return int((s.ExpiresAt.Sub(now) - 1) / PeriodLength)
+}
+
+// Option is one of the memberships players can buy.
+type Option struct {
+	Periods int
+	Credits int
+}
+
+// Config is the Habbo Club configuration.
+type Config struct {
+	Options []Option // chosen by the client with their 1 based position
+	Gifts   []string // sprite of the gift given for each period in order, the last one is repeated after that
+}
+
+// giftFor returns the sprite of the gift given after the given number of gifts, or an empty string if there are no
+// gifts configured.
+func (c *Config) giftFor(given int) string {
+	if len(c.Gifts) == 0 {
+		return ""
+	}
+	if given >= len(c.Gifts) {
+		given = len(c.Gifts) - 1
+	}
+	return c.Gifts[given]
+}
@@ -0,0 +1,117 @@
+package club
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jtieri/habbgo/game/credits"
+)
+
+type ClubRepo struct {
+	database *sql.DB
+}
+
+// NewClubRepo returns a new instance of ClubRepo.
+func NewClubRepo(db *sql.DB) *ClubRepo {
+	return &ClubRepo{database: db}
+}
+
+// Subscription returns the player's membership, or nil if they have never been a member.
+func (cr *ClubRepo) Subscription(playerId int) (*Subscription, error) {
+	return subscription(cr.database, playerId, false)
+}
+
+// queryRower is implemented by both sql.DB & sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func subscription(db queryRower, playerId int, lock bool) (*Subscription, error) {
+	query := "SELECT C.player_id, C.started_at, C.expires_at, C.next_gift_at, C.gifts_given " +
+		"FROM club_subscriptions C WHERE C.player_id = $1"
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	s := &Subscription{}
+	err := db.QueryRow(query, playerId).Scan(&s.PlayerId, &s.StartedAt, &s.ExpiresAt, &s.NextGiftAt, &s.GiftsGiven)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return s, nil
+}
+
+// Purchase takes the option's price from the player's credits & adds its periods to their membership, starting a
+// new one if they aren't a member at now. credits.ErrNoCredits is returned if the player can't afford it.
+// The updated membership & the player's remaining credits are returned.
+func (cr *ClubRepo) Purchase(playerId int, option Option, now time.Time) (*Subscription, int, error) {
+	tx, err := cr.database.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	balance, err := credits.Change(tx, playerId, -option.Credits, credits.Club,
+		fmt.Sprintf("club_habbo %d", option.Periods))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s, err := subscription(tx, playerId, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	length := time.Duration(option.Periods) * PeriodLength
+	switch {
+	case s == nil:
+		s = &Subscription{PlayerId: playerId, StartedAt: now, ExpiresAt: now.Add(length), NextGiftAt: now.Add(PeriodLength)}
+	case s.Active(now):
+		s.ExpiresAt = s.ExpiresAt.Add(length)
+	default: // lapsed memberships start over, but keep counting gifts
+		s.StartedAt, s.ExpiresAt, s.NextGiftAt = now, now.Add(length), now.Add(PeriodLength)
+	}
+
+	_, err = tx.Exec("INSERT INTO club_subscriptions(player_id, started_at, expires_at, next_gift_at, gifts_given) "+
+		"VALUES($1, $2, $3, $4, $5) ON CONFLICT (player_id) DO UPDATE SET started_at = $2, expires_at = $3, "+
+		"next_gift_at = $4", playerId, s.StartedAt, s.ExpiresAt, s.NextGiftAt, s.GiftsGiven)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s, balance, tx.Commit()
+}
+
+// GiveGift records the player's next gift if one is due at now, returning its sprite. Gifts are due once per period
+// the player paid for, so an empty sprite is returned once they're up to date.
+// sprite picks the gift to give from how many were given before it.
+func (cr *ClubRepo) GiveGift(playerId int, now time.Time, sprite func(given int) string) (string, error) {
+	tx, err := cr.database.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var given int
+	err = tx.QueryRow("UPDATE club_subscriptions SET gifts_given = gifts_given + 1, "+
+		"next_gift_at = next_gift_at + $3 * INTERVAL '1 second' "+
+		"WHERE player_id = $1 AND next_gift_at <= $2 AND next_gift_at < expires_at RETURNING gifts_given",
+		playerId, now, int64(PeriodLength/time.Second)).Scan(&given)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	gift := sprite(given - 1)
+	if _, err := tx.Exec("INSERT INTO club_gifts(player_id, sprite, given_at) VALUES($1, $2, $3)",
+		playerId, gift, now); err != nil {
+		return "", err
+	}
+
+	return gift, tx.Commit()
+}
@@ -0,0 +1,64 @@
+package club
+
+import (
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ClubService sells Habbo Club memberships & hands out the monthly club gifts.
+type ClubService struct {
+	repo   *ClubRepo
+	config *Config
+
+	log *zap.Logger
+}
+
+func NewClubService(log *zap.Logger, db *sql.DB, config *Config) *ClubService {
+	return &ClubService{
+		repo:   NewClubRepo(db),
+		config: config,
+		log:    log,
+	}
+}
+
+// Options returns the memberships players can buy.
+func (cs *ClubService) Options() []Option {
+	return cs.config.Options
+}
+
+// Subscription returns the player's membership, or nil if they have never been a member.
+func (cs *ClubService) Subscription(playerId int) (*Subscription, error) {
+	return cs.repo.Subscription(playerId)
+}
+
+// Buy sells the player the membership option at the given 1 based position of Options.
+// The updated membership & the player's remaining credits are returned.
+func (cs *ClubService) Buy(playerId, choice int) (*Subscription, int, error) {
+	if choice < 1 || choice > len(cs.config.Options) {
+		return nil, 0, ErrUnknownOption
+	}
+	return cs.repo.Purchase(playerId, cs.config.Options[choice-1], time.Now().UTC())
+}
+
+// DeliverGifts gives the player every club gift that has become due, one for each period since the last one, and
+// returns their sprites. Nothing is given while no gifts are configured.
+func (cs *ClubService) DeliverGifts(playerId int) ([]string, error) {
+	if len(cs.config.Gifts) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	var gifts []string
+	for {
+		gift, err := cs.repo.GiveGift(playerId, now, cs.config.giftFor)
+		if err != nil {
+			return gifts, err
+		}
+		if gift == "" {
+			return gifts, nil
+		}
+		gifts = append(gifts, gift)
+	}
+}
@@ -0,0 +1,40 @@
+package club
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription(t *testing.T) {
+	start := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := &Subscription{StartedAt: start, ExpiresAt: start.Add(3 * PeriodLength)}
+
+	require.True(t, s.Active(start))
+	require.Equal(t, 31, s.DaysLeft(start))
+	require.Equal(t, 0, s.ElapsedPeriods(start))
+	require.Equal(t, 2, s.PrepaidPeriods(start))
+
+	now := start.Add(PeriodLength + 10*24*time.Hour + time.Hour)
+	require.Equal(t, 21, s.DaysLeft(now))
+	require.Equal(t, 1, s.ElapsedPeriods(now))
+	require.Equal(t, 1, s.PrepaidPeriods(now))
+
+	expired := start.Add(3 * PeriodLength)
+	require.False(t, s.Active(expired))
+	require.Equal(t, 0, s.DaysLeft(expired))
+	require.Equal(t, 0, s.PrepaidPeriods(expired))
+
+	var none *Subscription
+	require.False(t, none.Active(start))
+	require.Equal(t, 0, none.ElapsedPeriods(start))
+}
+
+func TestGiftFor(t *testing.T) {
+	config := &Config{Gifts: []string{"a", "b"}}
+	require.Equal(t, "a", config.giftFor(0))
+	require.Equal(t, "b", config.giftFor(1))
+	require.Equal(t, "b", config.giftFor(5))
+	require.Equal(t, "", (&Config{}).giftFor(0))
+}
@@ -0,0 +1,28 @@
+package credits
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoCredits = errors.New("not enough credits")
+
+// Reason says why a player's credits changed.
+type Reason string
+
+const (
+	Club    Reason = "club"    // bought or extended a Habbo Club membership
+	Voucher Reason = "voucher" // redeemed a credit voucher
+	Purse   Reason = "purse"   // bought tickets or film
+)
+
+// Transaction is a change to a player's credits, recorded in the ledger.
+type Transaction struct {
+	Id        int
+	PlayerId  int
+	Amount    int // negative when credits were spent
+	Balance   int // the player's credits after the change
+	Reason    Reason
+	Reference string // what the credits were spent on or came from, such as a voucher code
+	CreatedAt time.Time
+}
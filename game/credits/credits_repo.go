@@ -0,0 +1,84 @@
+package credits
+
+import (
+	"database/sql"
+	"time"
+)
+
+type CreditsRepo struct {
+	database *sql.DB
+}
+
+// NewCreditsRepo returns a new instance of CreditsRepo.
+func NewCreditsRepo(db *sql.DB) *CreditsRepo {
+	return &CreditsRepo{database: db}
+}
+
+// Balance returns the player's credits.
+func (cr *CreditsRepo) Balance(playerId int) (int, error) {
+	var balance int
+	err := cr.database.QueryRow("SELECT P.credits FROM players P WHERE P.id = $1", playerId).Scan(&balance)
+	return balance, err
+}
+
+// Change adds amount to the player's credits and records it in the ledger, returning their new balance.
+func (cr *CreditsRepo) Change(playerId, amount int, reason Reason, reference string) (int, error) {
+	tx, err := cr.database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	balance, err := Change(tx, playerId, amount, reason, reference)
+	if err != nil {
+		return 0, err
+	}
+	return balance, tx.Commit()
+}
+
+// Change adds amount to the player's credits and records it in the ledger as part of the transaction tx, returning
+// their new balance. ErrNoCredits is returned if it would leave the player with fewer than zero credits.
+func Change(tx *sql.Tx, playerId, amount int, reason Reason, reference string) (int, error) {
+	var balance int
+	err := tx.QueryRow("UPDATE players SET credits = credits + $1 WHERE id = $2 AND credits + $1 >= 0 "+
+		"RETURNING credits", amount, playerId).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, ErrNoCredits
+	} else if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("INSERT INTO credit_transactions(player_id, amount, balance, reason, reference, created_at) "+
+		"VALUES($1, $2, $3, $4, $5, $6)", playerId, amount, balance, string(reason), reference, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// Log returns the player's most recent transactions, newest first.
+func (cr *CreditsRepo) Log(playerId, limit int) ([]Transaction, error) {
+	rows, err := cr.database.Query(
+		"SELECT T.id, T.player_id, T.amount, T.balance, T.reason, T.reference, T.created_at "+
+			"FROM credit_transactions T WHERE T.player_id = $1 ORDER BY T.created_at DESC, T.id DESC LIMIT $2",
+		playerId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		var (
+			t      Transaction
+			reason string
+		)
+		if err := rows.Scan(&t.Id, &t.PlayerId, &t.Amount, &t.Balance, &reason, &t.Reference, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.Reason = Reason(reason)
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
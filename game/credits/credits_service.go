@@ -0,0 +1,63 @@
+package credits
+
+import (
+	"database/sql"
+
+	"go.uber.org/zap"
+)
+
+// LogLength is how many transactions are shown in a player's credit log.
+const LogLength = 50
+
+// CreditsService changes player credits, every change is recorded in the credit_transactions ledger.
+type CreditsService struct {
+	repo *CreditsRepo
+
+	log *zap.Logger
+}
+
+func NewCreditsService(log *zap.Logger, db *sql.DB) *CreditsService {
+	return &CreditsService{
+		repo: NewCreditsRepo(db),
+		log:  log,
+	}
+}
+
+// Balance returns the player's credits.
+func (cs *CreditsService) Balance(playerId int) (int, error) {
+	return cs.repo.Balance(playerId)
+}
+
+// Give adds credits to the player's balance and returns their new balance.
+func (cs *CreditsService) Give(playerId, amount int, reason Reason, reference string) (int, error) {
+	balance, err := cs.repo.Change(playerId, amount, reason, reference)
+	if err != nil {
+		cs.log.Warn("Failed to change credits",
+			zap.Int("player_id", playerId),
+			zap.Int("amount", amount),
+			zap.String("reason", string(reason)),
+			zap.Error(err),
+		)
+	}
+	return balance, err
+}
+
+// Take removes credits from the player's balance and returns their new balance. ErrNoCredits is returned if the
+// player can't afford it.
+func (cs *CreditsService) Take(playerId, amount int, reason Reason, reference string) (int, error) {
+	balance, err := cs.repo.Change(playerId, -amount, reason, reference)
+	if err != nil && err != ErrNoCredits {
+		cs.log.Warn("Failed to change credits",
+			zap.Int("player_id", playerId),
+			zap.Int("amount", -amount),
+			zap.String("reason", string(reason)),
+			zap.Error(err),
+		)
+	}
+	return balance, err
+}
+
+// Log returns the player's most recent transactions, newest first.
+func (cs *CreditsService) Log(playerId int) ([]Transaction, error) {
+	return cs.repo.Log(playerId, LogLength)
+}
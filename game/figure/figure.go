@@ -0,0 +1,168 @@
+package figure
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const partLength = 5 // three digit set ID followed by a two digit colour
+
+var (
+	ErrMalformed  = errors.New("figure is malformed")
+	ErrUnknownSet = errors.New("figure uses an unknown set")
+	ErrWrongSex   = errors.New("figure uses a set for the other sex")
+	ErrClubOnly   = errors.New("figure uses a club only set")
+	ErrColour     = errors.New("figure uses a colour outside of its part's palette")
+	ErrParts      = errors.New("figure must use exactly one set of every part")
+)
+
+//go:embed figuredata.yml
+var defaultData []byte
+
+// Set is a clothing set that can be chosen in the figure editor.
+type Set struct {
+	ID     int
+	Part   string // hr, hd, ch, lg or sh
+	Sex    string // M or F
+	Club   bool   // only wearable by Habbo Club members
+	Colors int    // colours 1 up to Colors can be used with the set
+}
+
+// Data holds every clothing set that can be chosen in the figure editor, it's used to validate figures & to build
+// the list of sets sent in AVAILABLESETS.
+type Data struct {
+	parts []string
+	sets  map[int]*Set
+}
+
+// dataFile is the layout of the figure data file, see figuredata.yml.
+type dataFile struct {
+	Parts []struct {
+		Type    string `yaml:"type"`
+		Colours int    `yaml:"colours"`
+		Male    []int  `yaml:"male"`
+		Female  []int  `yaml:"female"`
+		Club    []int  `yaml:"club"`
+	} `yaml:"parts"`
+}
+
+// Default returns the figure Data built into habbgo.
+func Default() *Data {
+	data, err := Parse(defaultData)
+	if err != nil {
+		panic(fmt.Sprintf("built in figure data is invalid: %v", err))
+	}
+	return data
+}
+
+// Load reads figure Data from the YAML file at path.
+func Load(path string) (*Data, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(raw)
+}
+
+// Parse builds figure Data from YAML laid out like figuredata.yml.
+func Parse(raw []byte) (*Data, error) {
+	var file dataFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, err
+	}
+
+	data := &Data{sets: make(map[int]*Set)}
+	for _, part := range file.Parts {
+		if part.Type == "" || part.Colours < 1 {
+			return nil, fmt.Errorf("part %q needs a type and at least one colour", part.Type)
+		}
+		data.parts = append(data.parts, part.Type)
+
+		club := make(map[int]bool, len(part.Club))
+		for _, id := range part.Club {
+			club[id] = true
+		}
+
+		for sex, ids := range map[string][]int{"M": part.Male, "F": part.Female} {
+			for _, id := range ids {
+				if id < 100 || id > 999 {
+					return nil, fmt.Errorf("set %d of part %s isn't a three digit ID", id, part.Type)
+				}
+				if _, ok := data.sets[id]; ok {
+					return nil, fmt.Errorf("set %d is listed more than once", id)
+				}
+
+				data.sets[id] = &Set{ID: id, Part: part.Type, Sex: sex, Club: club[id], Colors: part.Colours}
+				delete(club, id)
+			}
+		}
+
+		for id := range club {
+			return nil, fmt.Errorf("club set %d of part %s isn't a male or female set", id, part.Type)
+		}
+	}
+
+	return data, nil
+}
+
+// Validate checks that figure is made up of exactly one known set of every part, that every set can be worn by a
+// player of the given sex & club membership and that the colours chosen are in each part's palette.
+func (d *Data) Validate(figure, sex string, club bool) error {
+	if figure == "" || len(figure) != partLength*len(d.parts) {
+		return ErrMalformed
+	}
+
+	used := make(map[string]bool, len(d.parts))
+	for i := 0; i < len(figure); i += partLength {
+		id, err := strconv.Atoi(figure[i : i+3])
+		if err != nil {
+			return ErrMalformed
+		}
+		colour, err := strconv.Atoi(figure[i+3 : i+partLength])
+		if err != nil {
+			return ErrMalformed
+		}
+
+		set, ok := d.sets[id]
+		switch {
+		case !ok:
+			return ErrUnknownSet
+		case set.Sex != sex:
+			return ErrWrongSex
+		case set.Club && !club:
+			return ErrClubOnly
+		case colour < 1 || colour > set.Colors:
+			return ErrColour
+		case used[set.Part]:
+			return ErrParts
+		}
+		used[set.Part] = true
+	}
+
+	return nil
+}
+
+// AvailableSets returns the IDs of every set, in ascending order, leaving out club only sets unless club is true.
+func (d *Data) AvailableSets(club bool) []int {
+	ids := make([]int, 0, len(d.sets))
+	for id, set := range d.sets {
+		if set.Club && !club {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// Set returns the Set with the given ID, or nil if there is no such set.
+func (d *Data) Set(id int) *Set {
+	return d.sets[id]
+}
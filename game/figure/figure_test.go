@@ -0,0 +1,46 @@
+package figure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	data := Default()
+
+	require.NoError(t, data.Validate("1000118001270012900121001", "M", false))
+	require.NoError(t, data.Validate("5000158001670016900160001", "F", false))
+
+	require.ErrorIs(t, data.Validate("", "M", false), ErrMalformed)
+	require.ErrorIs(t, data.Validate("10001180012700129001", "M", false), ErrMalformed)
+	require.ErrorIs(t, data.Validate("1000118001270012900121a01", "M", false), ErrMalformed)
+	require.ErrorIs(t, data.Validate("1010118001270012900121001", "M", false), ErrUnknownSet)
+	require.ErrorIs(t, data.Validate("1000118001270012900121001", "F", false), ErrWrongSex)
+	require.ErrorIs(t, data.Validate("1000118001270012900120601", "M", false), ErrClubOnly)
+	require.NoError(t, data.Validate("1000118001270012900120601", "M", true))
+	require.ErrorIs(t, data.Validate("1000018001270012900121001", "M", false), ErrColour)
+	require.ErrorIs(t, data.Validate("1002118001270012900121001", "M", false), ErrColour)
+	require.ErrorIs(t, data.Validate("1000110501270012900121001", "M", false), ErrParts)
+}
+
+func TestAvailableSets(t *testing.T) {
+	data := Default()
+
+	sets := data.AvailableSets(false)
+	require.NotContains(t, sets, 176)
+	require.Contains(t, sets, 175)
+	require.IsIncreasing(t, sets)
+
+	club := data.AvailableSets(true)
+	require.Contains(t, club, 176)
+	require.Len(t, club, 104)
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse([]byte("parts:\n  - type: hr\n    colours: 1\n    male: [100]\n    female: [100]\n"))
+	require.Error(t, err)
+
+	_, err = Parse([]byte("parts:\n  - type: hr\n    colours: 1\n    male: [100]\n    club: [105]\n"))
+	require.Error(t, err)
+}
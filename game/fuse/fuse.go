@@ -0,0 +1,35 @@
+package fuse
+
+// Right is a named permission, a fuse right, that the client and server check before letting a player do something.
+// The client is told which rights the player has with the RIGHTS message.
+type Right string
+
+// None is the right every player has, used where something isn't restricted at all.
+const None Right = ""
+
+const (
+	Login                 Right = "fuse_login"
+	BuyCredits            Right = "fuse_buy_credits"
+	Trade                 Right = "fuse_trade"
+	RoomQueueDefault      Right = "fuse_room_queue_default"
+	ExtendedBuddyList     Right = "fuse_extended_buddylist"
+	HabboChooser          Right = "fuse_habbo_chooser"
+	FurniChooser          Right = "fuse_furni_chooser"
+	RoomQueueClub         Right = "fuse_room_queue_club"
+	PriorityAccess        Right = "fuse_priority_access"
+	SpecialRoomLayouts    Right = "fuse_use_special_room_layouts"
+	ClubDance             Right = "fuse_use_club_dance"
+	AccessStaffCategories Right = "fuse_access_staff_categories"
+	EnterFullRooms        Right = "fuse_enter_full_rooms"
+	Alert                 Right = "fuse_alert"
+	Kick                  Right = "fuse_kick"
+	SeeAllRoomOwners      Right = "fuse_see_all_roomowners"
+	SetFlatCatStaff       Right = "fuse_setflatcat_staff"
+	ModeratorAccess       Right = "fuse_moderator_access"
+	Ban                   Right = "fuse_ban"
+	EnterLockedRooms      Right = "fuse_enter_locked_rooms"
+	AnyRoomController     Right = "fuse_any_room_controller"
+	SeeFlatIds            Right = "fuse_see_flat_ids"
+	SetFlatCatPublic      Right = "fuse_setflatcat_public"
+	AdministratorAccess   Right = "fuse_administrator_access"
+)
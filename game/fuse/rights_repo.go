@@ -0,0 +1,47 @@
+package fuse
+
+import (
+	"database/sql"
+
+	"github.com/jtieri/habbgo/game/ranks"
+)
+
+// grant gives a right to every player of minRank or higher, only while they're a club member if clubOnly is set.
+type grant struct {
+	right    Right
+	minRank  ranks.Rank
+	clubOnly bool
+}
+
+type RightsRepo struct {
+	database *sql.DB
+}
+
+// NewRightsRepo returns a new instance of RightsRepo.
+func NewRightsRepo(db *sql.DB) *RightsRepo {
+	return &RightsRepo{database: db}
+}
+
+// grants returns every right granted in the fuse_rights table.
+func (rr *RightsRepo) grants() ([]grant, error) {
+	rows, err := rr.database.Query("SELECT F.fuse, F.min_rank, F.club_only FROM fuse_rights F ORDER BY F.min_rank, F.fuse")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []grant
+	for rows.Next() {
+		var (
+			g    grant
+			rank int
+		)
+		if err := rows.Scan(&g.right, &rank, &g.clubOnly); err != nil {
+			return nil, err
+		}
+		g.minRank = ranks.Rank(rank)
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
@@ -0,0 +1,83 @@
+package fuse
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/jtieri/habbgo/game/ranks"
+	"go.uber.org/zap"
+)
+
+// RightsService caches which rights each rank has, with & without a club membership.
+type RightsService struct {
+	repo *RightsRepo
+
+	mux    sync.RWMutex
+	grants []grant
+
+	log *zap.Logger
+}
+
+func NewRightsService(log *zap.Logger, db *sql.DB) *RightsService {
+	return &RightsService{
+		repo: NewRightsRepo(db),
+		log:  log,
+	}
+}
+
+// Build loads the rights granted to each rank into the cache.
+func (rs *RightsService) Build() {
+	if err := rs.Reload(); err != nil {
+		rs.log.Warn("Failed to load fuse rights", zap.Error(err))
+	}
+}
+
+// Reload replaces the cached rights with the ones in the database.
+func (rs *RightsService) Reload() error {
+	grants, err := rs.repo.grants()
+	if err != nil {
+		return err
+	}
+
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	rs.grants = grants
+	return nil
+}
+
+// Rights returns every right a player of the given rank & club membership has, without duplicates.
+func (rs *RightsService) Rights(rank ranks.Rank, club bool) []Right {
+	rs.mux.RLock()
+	defer rs.mux.RUnlock()
+
+	seen := make(map[Right]bool)
+	var rights []Right
+	for _, g := range rs.grants {
+		if g.applies(rank, club) && !seen[g.right] {
+			seen[g.right] = true
+			rights = append(rights, g.right)
+		}
+	}
+	return rights
+}
+
+// HasRight reports whether a player of the given rank & club membership has right. Everyone has the None right.
+func (rs *RightsService) HasRight(rank ranks.Rank, club bool, right Right) bool {
+	if right == None {
+		return true
+	}
+
+	rs.mux.RLock()
+	defer rs.mux.RUnlock()
+
+	for _, g := range rs.grants {
+		if g.right == right && g.applies(rank, club) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g grant) applies(rank ranks.Rank, club bool) bool {
+	return rank >= g.minRank && (club || !g.clubOnly)
+}
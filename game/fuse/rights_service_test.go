@@ -0,0 +1,28 @@
+package fuse
+
+import (
+	"testing"
+
+	"github.com/jtieri/habbgo/game/ranks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRights(t *testing.T) {
+	rs := &RightsService{grants: []grant{
+		{right: Login, minRank: ranks.Normal},
+		{right: ClubDance, minRank: ranks.Normal, clubOnly: true},
+		{right: ClubDance, minRank: ranks.Moderator},
+		{right: Ban, minRank: ranks.Moderator},
+	}}
+
+	require.Equal(t, []Right{Login}, rs.Rights(ranks.Normal, false))
+	require.Equal(t, []Right{Login, ClubDance}, rs.Rights(ranks.Normal, true))
+	require.Equal(t, []Right{Login, ClubDance, Ban}, rs.Rights(ranks.Administrator, false))
+	require.Empty(t, rs.Rights(ranks.None, true))
+
+	require.True(t, rs.HasRight(ranks.None, false, None))
+	require.False(t, rs.HasRight(ranks.Normal, false, ClubDance))
+	require.True(t, rs.HasRight(ranks.Normal, true, ClubDance))
+	require.True(t, rs.HasRight(ranks.Moderator, false, ClubDance))
+	require.False(t, rs.HasRight(ranks.Guide, true, Ban))
+}
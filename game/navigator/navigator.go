@@ -1,20 +1,24 @@
 package navigator
 
 import (
-	"github.com/jtieri/habbgo/game/ranks"
+	"github.com/jtieri/habbgo/game/fuse"
 )
 
+// PrivateRoomLimit is the most guest rooms listed in a navigator category.
+const PrivateRoomLimit = 50
+
 type Navigator struct {
 	Categories []Category
 }
 
 type Category struct {
-	ID             int
-	ParentID       int
-	Name           string
-	IsNode         bool
-	IsPublic       bool
-	IsTrading      bool
-	MinRankAccess  ranks.Rank
-	MinRankSetFlat int
+	ID           int
+	ParentID     int
+	Name         string
+	IsNode       bool
+	IsPublic     bool
+	IsTrading    bool
+	AccessRight  fuse.Right // needed to see the category
+	SetFlatRight fuse.Right // needed to put rooms in the category
+	ClubOnly     bool       // only Habbo Club members can browse the category & enter its rooms
 }
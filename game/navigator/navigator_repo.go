@@ -17,7 +17,7 @@ func NewNavRepo(db *sql.DB) *NavRepo {
 // Categories retrieves the navigator categories found in database table room_categories and returns them as a slice of
 // Category structs.
 func (navRepo *NavRepo) Categories() []Category {
-	rows, err := navRepo.database.Query("SELECT id, parent_id, is_node, name, is_public, is_trading, min_rank_access, min_rank_setflatcat FROM room_categories")
+	rows, err := navRepo.database.Query("SELECT id, parent_id, is_node, name, is_public, is_trading, access_right, setflatcat_right, club_only FROM room_categories")
 	if err != nil {
 		log.Printf("%v", err)
 	}
@@ -26,7 +26,7 @@ func (navRepo *NavRepo) Categories() []Category {
 	var categories []Category
 	for rows.Next() {
 		var cat Category
-		err = rows.Scan(&cat.ID, &cat.ParentID, &cat.IsNode, &cat.Name, &cat.IsPublic, &cat.IsTrading, &cat.MinRankAccess, &cat.MinRankSetFlat)
+		err = rows.Scan(&cat.ID, &cat.ParentID, &cat.IsNode, &cat.Name, &cat.IsPublic, &cat.IsTrading, &cat.AccessRight, &cat.SetFlatRight, &cat.ClubOnly)
 		if err != nil {
 			log.Printf("%v", err)
 		}
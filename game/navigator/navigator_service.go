@@ -55,6 +55,39 @@ func (ns *NavService) CategoriesByParentId(pid int) []Category {
 	return categories
 }
 
+// ParentChain returns the ancestors of the category with the given id, starting with its parent and ending with the
+// root of the tree. Categories whose parent doesn't exist end the chain, as does a parent that's already in it.
+func (ns *NavService) ParentChain(id int) []Category {
+	var chain []Category
+
+	seen := map[int]bool{id: true}
+	cat := ns.CategoryById(id)
+	for cat != nil && !seen[cat.ParentID] {
+		parent := ns.CategoryById(cat.ParentID)
+		if parent == nil {
+			break
+		}
+		seen[parent.ID] = true
+		chain = append(chain, *parent)
+		cat = parent
+	}
+
+	return chain
+}
+
+// FlatCategories returns the categories guest rooms can be put in, those that aren't nodes or public.
+func (ns *NavService) FlatCategories() []Category {
+	var categories []Category
+
+	for _, cat := range ns.nav.Categories {
+		if !cat.IsNode && !cat.IsPublic {
+			categories = append(categories, cat)
+		}
+	}
+
+	return categories
+}
+
 func CurrentVisitors(cat *Category, rooms []*room.Room) int {
 	visitors := 0
 
@@ -0,0 +1,29 @@
+package navigator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentChain(t *testing.T) {
+	ns := &NavService{nav: &Navigator{Categories: []Category{
+		{ID: 3, ParentID: 0, Name: "Public Rooms"},
+		{ID: 5, ParentID: 3, Name: "Entertainment"},
+		{ID: 50, ParentID: 5, Name: "Cinemas"},
+		{ID: 60, ParentID: 61, Name: "Loop"},
+		{ID: 61, ParentID: 60, Name: "Back"},
+	}}}
+
+	chain := ns.ParentChain(50)
+	require.Len(t, chain, 2)
+	require.Equal(t, 5, chain[0].ID)
+	require.Equal(t, 3, chain[1].ID)
+
+	require.Empty(t, ns.ParentChain(3))
+	require.Empty(t, ns.ParentChain(404))
+
+	chain = ns.ParentChain(60)
+	require.Len(t, chain, 1)
+	require.Equal(t, 61, chain[0].ID)
+}
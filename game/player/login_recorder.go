@@ -0,0 +1,101 @@
+package player
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// loginQueueSize is how many logins & logouts can wait to be written before new ones are dropped.
+const loginQueueSize = 1024
+
+// loginEvent is a login or logout waiting to be written to the login history.
+type loginEvent struct {
+	player    *Player
+	login     bool
+	playerId  int
+	machineId string
+	address   string
+	at        time.Time
+}
+
+// loginRecorder writes logins & logouts to the login history from a single goroutine, so that packet handlers
+// never wait on the database and a logout is always written after the login it closes.
+type loginRecorder struct {
+	repo  *LoginRepo
+	queue chan loginEvent
+	open  map[*Player]int // login history entry of every player whose login has been written
+	log   *zap.Logger
+
+	mux     sync.Mutex
+	stopped bool
+	done    chan struct{}
+}
+
+func newLoginRecorder(log *zap.Logger, repo *LoginRepo) *loginRecorder {
+	return &loginRecorder{
+		repo:  repo,
+		queue: make(chan loginEvent, loginQueueSize),
+		open:  make(map[*Player]int),
+		log:   log,
+		done:  make(chan struct{}),
+	}
+}
+
+// enqueue hands an event to the recorder without blocking, dropping it if the queue is full.
+func (lr *loginRecorder) enqueue(e loginEvent) {
+	lr.mux.Lock()
+	defer lr.mux.Unlock()
+	if lr.stopped {
+		return
+	}
+
+	select {
+	case lr.queue <- e:
+	default:
+		lr.log.Warn("Login history queue is full, dropping event",
+			zap.Int("player_id", e.playerId),
+			zap.Bool("login", e.login),
+		)
+	}
+}
+
+// stop stops accepting events and waits for the ones already queued to be written.
+func (lr *loginRecorder) stop() {
+	lr.mux.Lock()
+	if !lr.stopped {
+		lr.stopped = true
+		close(lr.queue)
+	}
+	lr.mux.Unlock()
+
+	<-lr.done
+}
+
+// run writes queued events until the recorder is stopped.
+func (lr *loginRecorder) run() {
+	defer close(lr.done)
+
+	for e := range lr.queue {
+		if e.login {
+			id, err := lr.repo.RecordLogin(e.playerId, e.machineId, e.address, e.at)
+			if err != nil {
+				lr.log.Warn("Failed to record login", zap.Int("player_id", e.playerId), zap.Error(err))
+				continue
+			}
+			lr.open[e.player] = id
+			continue
+		}
+
+		id, ok := lr.open[e.player]
+		if !ok {
+			continue // the login was never written
+		}
+		delete(lr.open, e.player)
+
+		if err := lr.repo.RecordLogout(id, e.playerId, e.at); err != nil {
+			lr.log.Warn("Failed to record logout", zap.Int("player_id", e.playerId), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package player
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Login is one login recorded in the login history.
+type Login struct {
+	PlayerId    int
+	Username    string
+	MachineId   string
+	Address     string
+	LoggedInAt  time.Time
+	LoggedOutAt sql.NullTime // not valid while the session is still open, or if the server stopped before it closed
+}
+
+// Duration returns how long the session lasted, or zero if it hasn't ended.
+func (l Login) Duration() time.Duration {
+	if !l.LoggedOutAt.Valid {
+		return 0
+	}
+	return l.LoggedOutAt.Time.Sub(l.LoggedInAt)
+}
+
+// LoginRepo records when, and from which machine IDs & IP addresses, players log in and out.
+type LoginRepo struct {
+	database *sql.DB
+}
+
+// NewLoginRepo returns a new instance of LoginRepo.
+func NewLoginRepo(db *sql.DB) *LoginRepo {
+	return &LoginRepo{database: db}
+}
+
+// RecordLogin adds a login by the player from the given machine ID & IP address to the history, updates their
+// last online time and returns the ID of the history entry.
+func (lr *LoginRepo) RecordLogin(playerId int, machineId, address string, at time.Time) (int, error) {
+	tx, err := lr.database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(
+		"INSERT INTO login_history(player_id, machine_id, ip_address, logged_in_at) VALUES($1, $2, $3, $4) RETURNING id",
+		playerId, machineId, address, at).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("UPDATE players SET last_online = $1 WHERE id = $2", at, playerId); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// RecordLogout closes the history entry with the given ID, adds the length of the session to the player's total
+// online time and updates their last online time.
+func (lr *LoginRepo) RecordLogout(entryId, playerId int, at time.Time) error {
+	tx, err := lr.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var loggedInAt time.Time
+	err = tx.QueryRow("UPDATE login_history SET logged_out_at = $1 WHERE id = $2 RETURNING logged_in_at", at, entryId).
+		Scan(&loggedInAt)
+	if err != nil {
+		return err
+	}
+
+	seconds := int64(at.Sub(loggedInAt).Seconds())
+	_, err = tx.Exec("UPDATE players SET last_online = $1, online_seconds = online_seconds + $2 WHERE id = $3",
+		at, seconds, playerId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// History returns the player's most recent logins, newest first.
+func (lr *LoginRepo) History(playerId, limit int) ([]Login, error) {
+	return lr.query(
+		"SELECT L.player_id, P.username, L.machine_id, L.ip_address, L.logged_in_at, L.logged_out_at "+
+			"FROM login_history L JOIN players P ON P.id = L.player_id "+
+			"WHERE L.player_id = $1 ORDER BY L.logged_in_at DESC LIMIT $2", playerId, limit)
+}
+
+// OnlineTime returns the total time the player has spent logged in over every session that has ended.
+func (lr *LoginRepo) OnlineTime(playerId int) (time.Duration, error) {
+	var seconds int64
+	err := lr.database.QueryRow("SELECT P.online_seconds FROM players P WHERE P.id = $1", playerId).Scan(&seconds)
+	return time.Duration(seconds) * time.Second, err
+}
+
+// LinkedAccounts returns the latest login of every other account that has logged in from a machine ID or
+// IP address the given player has logged in from, which is a good sign of alt accounts.
+func (lr *LoginRepo) LinkedAccounts(playerId int) ([]Login, error) {
+	return lr.query(
+		"SELECT DISTINCT ON (L.player_id) L.player_id, P.username, L.machine_id, L.ip_address, L.logged_in_at, "+
+			"L.logged_out_at FROM login_history L JOIN players P ON P.id = L.player_id "+
+			"WHERE L.player_id != $1 AND ("+
+			"(L.machine_id != '' AND L.machine_id IN (SELECT machine_id FROM login_history WHERE player_id = $1)) OR "+
+			"L.ip_address IN (SELECT ip_address FROM login_history WHERE player_id = $1)) "+
+			"ORDER BY L.player_id, L.logged_in_at DESC", playerId)
+}
+
+// AccountsByMachineId returns the latest login of every account that has logged in from the given machine ID.
+func (lr *LoginRepo) AccountsByMachineId(machineId string) ([]Login, error) {
+	return lr.query(
+		"SELECT DISTINCT ON (L.player_id) L.player_id, P.username, L.machine_id, L.ip_address, L.logged_in_at, "+
+			"L.logged_out_at FROM login_history L JOIN players P ON P.id = L.player_id "+
+			"WHERE L.machine_id = $1 ORDER BY L.player_id, L.logged_in_at DESC", machineId)
+}
+
+func (lr *LoginRepo) query(query string, args ...interface{}) ([]Login, error) {
+	rows, err := lr.database.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logins []Login
+	for rows.Next() {
+		var l Login
+		if err := rows.Scan(&l.PlayerId, &l.Username, &l.MachineId, &l.Address, &l.LoggedInAt, &l.LoggedOutAt); err != nil {
+			return nil, err
+		}
+		logins = append(logins, l)
+	}
+
+	return logins, rows.Err()
+}
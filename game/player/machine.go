@@ -0,0 +1,34 @@
+package player
+
+import (
+	"strings"
+
+	"github.com/jtieri/habbgo/crypto"
+)
+
+const (
+	maxMachineIdLen  = 64
+	machineIdChars   = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ#-_"
+	machineTokenSize = 16 // bytes of randomness in a machine ID issued by the server
+)
+
+// ValidMachineId reports whether id looks like a machine ID the client could have stored,
+// anything else is treated as if the client didn't send one.
+func ValidMachineId(id string) bool {
+	if id == "" || len(id) > maxMachineIdLen {
+		return false
+	}
+
+	for _, c := range id {
+		if !strings.ContainsRune(machineIdChars, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewMachineId generates a fresh machine ID to hand to clients that don't have one yet.
+func NewMachineId() string {
+	return "#" + strings.ToUpper(crypto.GenerateToken(machineTokenSize))
+}
@@ -0,0 +1,107 @@
+package player
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+)
+
+// DefaultParentConfirmationLifetime is how long a parent has to confirm their child's account.
+const DefaultParentConfirmationLifetime = 14 * 24 * time.Hour
+
+var (
+	ErrInvalidConfirmation = errors.New("parent confirmation token is invalid or expired")
+	ErrConfirmationPending = errors.New("a parent confirmation is still pending")
+)
+
+// ParentRepo creates and consumes the tokens emailed to parents so that they can confirm their child's account,
+// stored in the parent_confirmations table.
+type ParentRepo struct {
+	database *sql.DB
+}
+
+// NewParentRepo returns a new instance of ParentRepo.
+func NewParentRepo(db *sql.DB) *ParentRepo {
+	return &ParentRepo{database: db}
+}
+
+// CreateConfirmation creates a confirmation token for the player, replacing any expired ones they had, and returns
+// it. ErrConfirmationPending is returned if one of their tokens can still be used.
+func (pr *ParentRepo) CreateConfirmation(playerId int, parentEmail string, lifetime time.Duration) (string, error) {
+	tx, err := pr.database.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT 1 FROM players WHERE id = $1 FOR UPDATE", playerId); err != nil {
+		return "", err
+	}
+
+	var pending bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM parent_confirmations WHERE player_id = $1 AND expires_at > $2)",
+		playerId, time.Now().UTC()).Scan(&pending)
+	if err != nil {
+		return "", err
+	}
+	if pending {
+		return "", ErrConfirmationPending
+	}
+
+	if _, err := tx.Exec("DELETE FROM parent_confirmations WHERE player_id = $1", playerId); err != nil {
+		return "", err
+	}
+
+	token := crypto.GenerateToken(crypto.TOKENSIZE)
+	_, err = tx.Exec(
+		"INSERT INTO parent_confirmations(token, player_id, parent_email, expires_at) VALUES($1, $2, $3, $4)",
+		token, playerId, parentEmail, time.Now().UTC().Add(lifetime))
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// Confirm burns the confirmation token and lifts the restrictions on the account it was created for, returning the
+// account's username.
+func (pr *ParentRepo) Confirm(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidConfirmation
+	}
+
+	tx, err := pr.database.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var (
+		id        int
+		expiresAt time.Time
+	)
+	err = tx.QueryRow("DELETE FROM parent_confirmations WHERE token = $1 RETURNING player_id, expires_at", token).
+		Scan(&id, &expiresAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", ErrInvalidConfirmation
+	case err != nil:
+		return "", err
+	case time.Now().UTC().After(expiresAt):
+		if err := tx.Commit(); err != nil {
+			return "", err
+		}
+		return "", ErrInvalidConfirmation
+	}
+
+	var username string
+	err = tx.QueryRow("UPDATE players SET parent_confirmed = true WHERE id = $1 RETURNING username", id).
+		Scan(&username)
+	if err != nil {
+		return "", err
+	}
+
+	return username, tx.Commit()
+}
@@ -0,0 +1,70 @@
+package player
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/text"
+)
+
+const (
+	AllowedChars      = "1234567890qwertyuiopasdfghjklzxcvbnm_-+=?!@:.,$" // TODO make this a config option
+	MinPasswordLength = 6
+	MaxPasswordLength = 16
+)
+
+var (
+	ErrPasswordTooShort      = errors.New("password is too short")
+	ErrPasswordTooLong       = errors.New("password is too long")
+	ErrPasswordUnacceptable  = errors.New("password contains characters that aren't allowed")
+	ErrPasswordHasNoNumber   = errors.New("password doesn't contain a number")
+	ErrPasswordSimilarToName = errors.New("password is the same as the username")
+)
+
+// ValidatePassword returns why password can't be used by the player with the given username, or nil if it can.
+func ValidatePassword(username, password string) error {
+	switch {
+	case len(password) < MinPasswordLength:
+		return ErrPasswordTooShort
+	case len(password) > MaxPasswordLength:
+		return ErrPasswordTooLong
+	case !text.ContainsAllowedChars(strings.ToLower(password), AllowedChars) || strings.Contains(username, " "):
+		return ErrPasswordUnacceptable
+	case !text.ContainsANumber(password):
+		return ErrPasswordHasNoNumber
+	case username == password:
+		return ErrPasswordSimilarToName
+	default:
+		return nil
+	}
+}
+
+// PasswordGuard gives password handling outside of the game server, such as the web site's password reset page,
+// the game's limits: attempts per key within a window, and at most maxHashing passwords hashed at once.
+type PasswordGuard struct {
+	attempts *throttle
+	hashing  chan struct{}
+}
+
+// NewPasswordGuard returns a PasswordGuard allowing limit attempts per key within window.
+func NewPasswordGuard(limit int, window time.Duration) *PasswordGuard {
+	return &PasswordGuard{
+		attempts: newThrottle(limit, window),
+		hashing:  make(chan struct{}, maxHashing),
+	}
+}
+
+// Allow records an attempt for key, such as an IP address, and reports whether it is within the limit.
+func (pg *PasswordGuard) Allow(key string) bool {
+	return pg.attempts.allow(key)
+}
+
+// Hash hashes password with hasher, waiting while maxHashing other passwords are being hashed.
+func (pg *PasswordGuard) Hash(hasher crypto.PasswordHasher, password string) (string, error) {
+	pg.hashing <- struct{}{}
+	defer func() { <-pg.hashing }()
+
+	return hasher.Hash(password)
+}
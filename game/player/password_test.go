@@ -0,0 +1,16 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePassword(t *testing.T) {
+	require.NoError(t, ValidatePassword("lazar", "secret1"))
+	require.Equal(t, ErrPasswordTooShort, ValidatePassword("lazar", "abc1"))
+	require.Equal(t, ErrPasswordTooLong, ValidatePassword("lazar", "abcdefghijklmnop1"))
+	require.Equal(t, ErrPasswordUnacceptable, ValidatePassword("lazar", "secret 1"))
+	require.Equal(t, ErrPasswordHasNoNumber, ValidatePassword("lazar", "secrets"))
+	require.Equal(t, ErrPasswordSimilarToName, ValidatePassword("lazar1", "lazar1"))
+}
@@ -5,7 +5,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jtieri/habbgo/game/ban"
+	"github.com/jtieri/habbgo/game/club"
+	"github.com/jtieri/habbgo/game/fuse"
 	"github.com/jtieri/habbgo/game/navigator"
+	"github.com/jtieri/habbgo/game/purse"
 	"github.com/jtieri/habbgo/game/ranks"
 	"github.com/jtieri/habbgo/game/room"
 	"github.com/jtieri/habbgo/protocol/packets"
@@ -13,8 +17,22 @@ import (
 )
 
 type Player struct {
-	Session Session
-	Details *Details
+	Session   Session
+	Details   *Details
+	MachineId string // persistent machine identifier sent by the client in UNIQUEID
+
+	// Preferences are loaded when the player logs in and are nil until then.
+	Preferences *Preferences
+
+	// Club is the player's Habbo Club membership, nil if they have never been a member.
+	Club *club.Subscription
+
+	// RoomId is the room the player is in, 0 when they aren't in a room, RoomUnitId is their instance ID in that room
+	// and LetInto is the guest room TRYFLAT let them into. They're guarded by the PlayerService, read them with
+	// PlayerService.Room.
+	RoomId     int
+	RoomUnitId int
+	LetInto    int
 
 	Database *sql.DB
 	Services ServiceManager
@@ -38,14 +56,15 @@ type Details struct {
 	PlayerRank   ranks.Rank
 	CurrentBadge string
 	DisplayBadge bool
-	SoundEnabled bool
+	// ParentConfirmed is false while an under age account is waiting for a parent to confirm it, see Restricted.
+	ParentConfirmed bool
 }
 
 type Session interface {
 	Listen()
 	Send(caller interface{}, packet *packets.OutgoingPacket)
 	Queue(packet *packets.OutgoingPacket)
-	Flush(caller interface{}, packet *packets.OutgoingPacket)
+	Flush(caller interface{})
 	Address() string
 	GetPacketCommand(headerId int) (func(*Player, *packets.IncomingPacket), bool)
 	Close()
@@ -94,27 +113,170 @@ func PlayerRank(rankString string) ranks.Rank {
 	}
 }
 
+// Banned returns the first active ban on the player's account, IP address or machine ID, or nil if they aren't banned.
+func (p *Player) Banned() *ban.Ban {
+	return p.Services.PlayerService().Bans().Check(p.Details.Id, p.Session.Address(), p.MachineId)
+}
+
+// Login finishes logging in a player whose credentials have been verified. Callers should check that the
+// player isn't Banned first.
 func (p *Player) Login() {
 	// Set player logged in & ping ready for latency test
-	// Possibly add player to a list of online players? Health endpoint with server stats?
+	// Health endpoint with server stats?
 	// Save current time to Conn for players last online time
 
-	// Check if player is banned & if so send USER_BANNED
-	// Log IP address to Conn
-
+	ps := p.Services.PlayerService()
+	ps.AddOnlinePlayer(p)
+	ps.RecordLogin(p)
 	LoadBadges(p)
 
+	prefs, err := ps.Preferences().Load(p.Details.Id, ps.Config().DefaultPreferences)
+	if err != nil {
+		p.log.Warn("Failed to load player preferences, using the defaults",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+		defaults := ps.Config().DefaultPreferences
+		prefs = &defaults
+	}
+	p.Preferences = prefs
+
+	p.LoadClub()
+
 	// If Config has alerts enabled, send player ALERT
+}
 
-	// Check if player gets club gift & update club status
+// LoadClub refreshes the player's Habbo Club membership.
+func (p *Player) LoadClub() {
+	s, err := p.Services.PlayerService().Club().Subscription(p.Details.Id)
+	if err != nil {
+		p.log.Warn("Failed to load club membership",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+		return
+	}
+	p.Club = s
 }
 
-func (p *Player) Register(username, figure, gender, email, birthday, createdAt, password string, salt []byte) {
-	err := Register(p, username, figure, gender, email, birthday, createdAt, password, salt)
+// RefreshCredits reloads the player's credits, which can be changed by other players & the web site.
+func (p *Player) RefreshCredits() {
+	balance, err := p.Services.PlayerService().Credits().Balance(p.Details.Id)
 	if err != nil {
+		p.log.Warn("Failed to load credits",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+		return
+	}
+	p.Details.Credits = balance
+}
+
+// UseTickets takes tickets from the player before they play a game, purse.ErrNotEnough is returned if they don't
+// have enough.
+func (p *Player) UseTickets(amount int) error {
+	_, err := p.Services.PlayerService().UsePurseItem(p.Details.Id, purse.Tickets, amount)
+	return err
+}
+
+// UseFilm takes film from the player when they take a photo, purse.ErrNotEnough is returned if they don't have
+// enough.
+func (p *Player) UseFilm(amount int) error {
+	_, err := p.Services.PlayerService().UsePurseItem(p.Details.Id, purse.Film, amount)
+	return err
+}
+
+func (p *Player) setPurseItem(item purse.Item, amount int) {
+	switch item {
+	case purse.Tickets:
+		p.Details.Tickets = amount
+	case purse.Film:
+		p.Details.Film = amount
+	}
+}
+
+// ClubMember reports whether the player is a Habbo Club member.
+func (p *Player) ClubMember() bool {
+	return p.Club.Active(time.Now())
+}
+
+// DeliverClubGifts gives the player the club gifts that have become due and returns their sprites.
+func (p *Player) DeliverClubGifts() []string {
+	gifts, err := p.Services.PlayerService().Club().DeliverGifts(p.Details.Id)
+	if err != nil {
+		p.log.Warn("Failed to deliver club gifts",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+	}
+	return gifts
+}
+
+// CanEnterRoom reports whether the player may enter the room, rooms in club-only categories are for club members.
+func (p *Player) CanEnterRoom(r *room.Room) bool {
+	category := p.Services.NavigatorService().CategoryById(r.Details.CategoryID)
+	return category == nil || !category.ClubOnly || p.ClubMember()
+}
+
+// HasRight reports whether the player's rank gives them the fuse right.
+func (p *Player) HasRight(right fuse.Right) bool {
+	return p.Services.PlayerService().Rights().HasRight(p.Details.PlayerRank, p.ClubMember(), right)
+}
+
+// Rights returns every fuse right the player has.
+func (p *Player) Rights() []fuse.Right {
+	return p.Services.PlayerService().Rights().Rights(p.Details.PlayerRank, p.ClubMember())
+}
+
+// CanSetFlatCategory reports whether the player may put guest rooms in the category.
+func (p *Player) CanSetFlatCategory(category *navigator.Category) bool {
+	return !category.IsNode && !category.IsPublic && p.HasRight(category.AccessRight) &&
+		p.HasRight(category.SetFlatRight)
+}
+
+// Restricted reports whether the player's account is waiting for a parent to confirm it. Restricted players can
+// log in and look around, but can't edit their profile or account, spend credits, redeem vouchers or create &
+// describe rooms until it's confirmed.
+func (p *Player) Restricted() bool {
+	return p.Details.Id != 0 && !p.Details.ParentConfirmed
+}
+
+// SavePreferences stores the player's Preferences after they've been changed.
+func (p *Player) SavePreferences() error {
+	if p.Preferences == nil {
+		return nil
+	}
+
+	err := p.Services.PlayerService().Preferences().Save(p.Details.Id, p.Preferences)
+	if err != nil {
+		p.log.Warn("Failed to save player preferences",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// Logout marks the player as offline once their Session has closed and records how long they were online.
+func (p *Player) Logout() {
+	if p.Details.Id == 0 {
+		return
+	}
+
+	p.Services.PlayerService().LeaveRoom(p)
+	p.Services.PlayerService().RemoveOnlinePlayer(p)
+	p.Services.PlayerService().RecordLogout(p)
+}
+
+func (p *Player) Register(username, figure, sex, motto, email string, birthday time.Time, passwordHash string,
+	parentConfirmed bool, prefs *Preferences) error {
+	_, err := Register(p, username, figure, sex, motto, email, birthday, time.Now().UTC(), passwordHash,
+		parentConfirmed, prefs)
+	if err != nil && err != ErrNameTaken {
 		p.log.Warn("Failed to register player",
 			zap.String("username", username),
 			zap.Error(err),
 		)
 	}
+	return err
 }
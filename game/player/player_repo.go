@@ -1,73 +1,138 @@
 package player
 
 import (
+	"database/sql"
 	"log"
+	"time"
 
 	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/game/ranks"
 	"go.uber.org/zap"
 )
 
-func Register(player *Player, username, figure, gender, email, birthday, createdAt, password string, salt []byte) error {
-	stmt, err := player.Database.Prepare(
-		"INSERT INTO Players(username, figure, sex, email, birthday, created_on, password_hash, password_salt) VALUES($1, $2, $3, $4, $5, $6, $7, $8)")
-
+// Register creates a new player inside a transaction and returns their id. ErrNameTaken is returned if the username
+// was claimed by someone else after it was approved.
+func Register(player *Player, username, figure, sex, motto, email string, birthday, createdAt time.Time,
+	passwordHash string, parentConfirmed bool, prefs *Preferences) (int, error) {
+	tx, err := player.Database.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(username, figure, gender, email, birthday, createdAt, password, salt)
-	if err != nil {
-		return err
+	var id int
+	err = tx.QueryRow(
+		"INSERT INTO players(username, figure, sex, motto, email, birthday, created_on, password_hash, parent_confirmed) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (username) DO NOTHING RETURNING id",
+		username, figure, sex, motto, email, birthday, createdAt, passwordHash, parentConfirmed).Scan(&id)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, ErrNameTaken
+	case err != nil:
+		return 0, err
 	}
 
-	return nil
+	if err := savePreferences(tx, id, prefs); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
 }
 
 func LoginDB(player *Player, username string, password string) bool {
 	var (
+		id                int
 		psswrdHash, uname string
 		psswrdSalt        []byte
 	)
 
 	err := player.Database.QueryRow(
-		"SELECT P.password_hash, P.password_salt, P.username FROM Players P WHERE P.username = $1", username).
-		Scan(&psswrdHash, &psswrdSalt, &uname)
+		"SELECT P.id, P.password_hash, P.password_salt, P.username FROM Players P WHERE P.username = $1", username).
+		Scan(&id, &psswrdHash, &psswrdSalt, &uname)
 
+	ps := player.Services.PlayerService()
 	if err != nil {
+		if err == sql.ErrNoRows {
+			ps.VerifyPassword("", nil, password)
+		}
 		player.log.Warn("Failed to query database during login",
 			zap.String("username", username),
 			zap.Error(err),
 		)
+		return false
 	}
 
-	if crypto.HashPassword(password, psswrdSalt) == psswrdHash {
-		player.Details.Username = uname
-		fillDetails(player)
-		return true
+	match, rehash := ps.VerifyPassword(psswrdHash, psswrdSalt, password)
+	if !match {
+		return false
 	}
 
-	return false
+	// Hashes made by an older scheme, or with weaker parameters, are replaced while we have the plain password.
+	if rehash {
+		if err := UpdatePasswordHash(player, id, ps.PasswordHasher(), password); err != nil {
+			player.log.Warn("Failed to rehash password during login",
+				zap.String("username", username),
+				zap.Error(err),
+			)
+		}
+	}
+
+	player.Details.Username = uname
+	fillDetails(player)
+	return true
 }
 
-func LoadBadges(player *Player) {
-	rows, err := player.Database.Query("SELECT P.badge_id FROM player_badges P WHERE P.player_id = $1", player.Details.Id)
+// UpdatePasswordHash hashes password with hasher and stores it for the player with the given id.
+// The legacy salt column is cleared since the salt is now part of the encoded hash.
+func UpdatePasswordHash(player *Player, id int, hasher crypto.PasswordHasher, password string) error {
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = player.Database.Exec("UPDATE players SET password_hash = $1, password_salt = '' WHERE id = $2", hash, id)
+	return err
+}
+
+// LoginSSO consumes an SSO ticket minted for the player's IP address and, if it is valid,
+// loads the details of the player it was minted for.
+func LoginSSO(player *Player, ticket string) bool {
+	username, err := NewSSORepo(player.Database).ConsumeTicket(ticket, player.Session.Address())
 	if err != nil {
-		log.Printf("%v ", err) // TODO properly log error
+		player.log.Warn("Failed to login with SSO ticket",
+			zap.String("address", player.Session.Address()),
+			zap.Error(err),
+		)
+		return false
 	}
-	defer rows.Close()
 
-	var badges []string
-	for rows.Next() {
-		var badge string
-		err := rows.Scan(&badge)
-		if err != nil {
-			log.Printf("%v ", err) // TODO properly log error
-		}
+	player.Details.Username = username
+	fillDetails(player)
+	return true
+}
+
+// LoadBadges fills in the badges the player owns, including those implied by their rank, and the badge they wear.
+func LoadBadges(player *Player) {
+	bs := player.Services.PlayerService().Badges()
 
-		badges = append(badges, badge)
+	codes, err := bs.Badges(player.Details.Id, player.Details.PlayerRank)
+	if err != nil {
+		player.log.Warn("Failed to load player badges", zap.Int("player_id", player.Details.Id), zap.Error(err))
+	}
+	selection, err := bs.Selection(player.Details.Id)
+	if err != nil {
+		player.log.Warn("Failed to load player badge", zap.Int("player_id", player.Details.Id), zap.Error(err))
 	}
 
-	player.Details.Badges = badges
+	player.Details.Badges = codes
+	player.Details.CurrentBadge = ""
+	player.Details.DisplayBadge = selection.Visible
+	for _, code := range codes {
+		if code == selection.Code {
+			player.Details.CurrentBadge = code // a badge implied by a rank the player no longer has isn't worn
+		}
+	}
 }
 
 func PlayerExists(p *Player, username string) bool {
@@ -88,21 +153,101 @@ func PlayerExists(p *Player, username string) bool {
 	return false
 }
 
-func UpdateLastOnline(datetime string) {
+// LookupPlayerId returns the id of the player with the given username, if they exist.
+func LookupPlayerId(p *Player, username string) (int, bool) {
+	id, err := PlayerIdByName(p.Database, username)
+	if err != nil {
+		if err != ErrPlayerNotFound {
+			p.log.Warn("Failed to look up player",
+				zap.String("username", username),
+				zap.Error(err),
+			)
+		}
+		return 0, false
+	}
+
+	return id, true
+}
+
+// PlayerIdByName returns the id of the player with the given username, ignoring case, or ErrPlayerNotFound.
+func PlayerIdByName(db *sql.DB, username string) (int, error) {
+	var id int
+	err := db.QueryRow("SELECT P.id FROM Players P WHERE LOWER(P.username) = LOWER($1)", username).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrPlayerNotFound
+	}
+	return id, err
+}
+
+// LookupPlayerRank returns the rank of the player with the given id, whether or not they're online.
+func LookupPlayerRank(p *Player, id int) (ranks.Rank, error) {
+	var rank string
+	err := p.Database.QueryRow("SELECT P.rank FROM Players P WHERE P.id = $1", id).Scan(&rank)
+	if err != nil {
+		return ranks.None, err
+	}
+	return PlayerRank(rank), nil
+}
+
+// UpdateProfile stores the player's figure, sex & motto and refreshes their Details.
+func UpdateProfile(p *Player, figure, sex, motto string) error {
+	_, err := p.Database.Exec("UPDATE players SET figure = $1, sex = $2, motto = $3 WHERE id = $4",
+		figure, sex, motto, p.Details.Id)
+	if err != nil {
+		return err
+	}
+
+	p.Details.Figure = figure
+	p.Details.Sex = sex
+	p.Details.Motto = motto
+	return nil
+}
+
+// UpdateEmail stores the player's new email address.
+func UpdateEmail(p *Player, email string) error {
+	_, err := p.Database.Exec("UPDATE players SET email = $1 WHERE id = $2", email, p.Details.Id)
+	return err
+}
+
+// CheckPassword reports whether password is the player's current password.
+func CheckPassword(p *Player, password string) bool {
+	var (
+		psswrdHash string
+		psswrdSalt []byte
+	)
+
+	err := p.Database.QueryRow("SELECT P.password_hash, P.password_salt FROM Players P WHERE P.id = $1", p.Details.Id).
+		Scan(&psswrdHash, &psswrdSalt)
+	if err != nil {
+		p.log.Warn("Failed to query password hash",
+			zap.Int("player_id", p.Details.Id),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	match, _ := p.Services.PlayerService().VerifyPassword(psswrdHash, psswrdSalt, password)
+	return match
+}
 
+// Birthday returns the birthday the player registered with.
+func Birthday(p *Player) (time.Time, error) {
+	var birthday time.Time
+	err := p.Database.QueryRow("SELECT P.birthday FROM Players P WHERE P.id = $1", p.Details.Id).Scan(&birthday)
+	return birthday, err
 }
 
 func fillDetails(p *Player) {
 	query := "SELECT P.id, P.username, P.sex, P.figure, P.pool_figure, P.film, P.credits, P.tickets, P.motto, " +
-		"P.console_motto, P.last_online, P.sound_enabled, P.Rank " +
+		"P.console_motto, P.last_online, P.parent_confirmed, P.Rank " +
 		"FROM Players P " +
 		"WHERE P.username = $1"
 
 	var tmpRank string
 	err := p.Database.QueryRow(query, p.Details.Username).Scan(&p.Details.Id, &p.Details.Username,
 		&p.Details.Sex, &p.Details.Figure, &p.Details.PoolFigure, &p.Details.Film, &p.Details.Credits,
-		&p.Details.Tickets, &p.Details.Motto, &p.Details.ConsoleMotto, &p.Details.LastOnline, &p.Details.SoundEnabled,
-		&tmpRank)
+		&p.Details.Tickets, &p.Details.Motto, &p.Details.ConsoleMotto, &p.Details.LastOnline,
+		&p.Details.ParentConfirmed, &tmpRank)
 
 	if err != nil {
 		log.Printf("%v ", err) // TODO log database errors properly
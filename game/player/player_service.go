@@ -1,17 +1,508 @@
 package player
 
-import "go.uber.org/zap"
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/date"
+	"github.com/jtieri/habbgo/game/badge"
+	"github.com/jtieri/habbgo/game/ban"
+	"github.com/jtieri/habbgo/game/club"
+	"github.com/jtieri/habbgo/game/credits"
+	"github.com/jtieri/habbgo/game/figure"
+	"github.com/jtieri/habbgo/game/fuse"
+	"github.com/jtieri/habbgo/game/purse"
+	"github.com/jtieri/habbgo/game/voucher"
+	"github.com/jtieri/habbgo/mailer"
+	"go.uber.org/zap"
+)
+
+const (
+	LoginAttempts      = 5           // logins that can be tried from an IP address per LoginAttemptWindow
+	LoginAttemptWindow = time.Minute // window LoginAttempts are counted over
+
+	ResetAttempts      = 3                // password resets an IP address can request per ResetAttemptWindow
+	ResetAttemptWindow = 15 * time.Minute // window ResetAttempts are counted over
+
+	ParentEmailAttempts      = 3         // parent confirmation emails an IP address can send per ParentEmailAttemptWindow
+	ParentEmailAttemptWindow = time.Hour // window ParentEmailAttempts are counted over
+
+	// maxHashing is how many passwords are hashed at once, an argon2id hash takes 64 MiB with the default parameters.
+	maxHashing = 4
+)
+
+// Config is the player related game server configuration.
+type Config struct {
+	IssueMachineIds bool   // give clients that send an empty or malformed UNIQUEID a fresh machine ID
+	FigureData      string // path to a figure data file replacing the built in clothing sets, if set
+	ResetURL        string // web page password reset links point to, the token is appended as ?token=
+
+	Coppa              int    // 0 off, 1 under age players need a parent's agreement, 2 under age players can't register
+	CoppaAge           int    // players younger than this are under age
+	RequireParentEmail bool   // under age accounts are restricted until a parent confirms them by email
+	SendParentEmail    bool   // ask restricted players for their parent's email address again when they log in
+	ParentConfirmURL   string // web page parent confirmation links point to, the token is appended as ?token=
+
+	DefaultPreferences Preferences  // preferences of players who have never changed them
+	Club               club.Config  // Habbo Club prices & monthly gifts
+	VoucherEnabled     bool         // players can redeem vouchers in the purse
+	Purse              purse.Config // ticket & film prices
+	MaxFavouriteRooms  int          // most rooms a player can add to their favourites
+	MaxRoomsPerPlayer  int          // most guest rooms a player can own
+}
 
 type PlayerService struct {
+	config   *Config
+	hasher   crypto.PasswordHasher
+	bans     *ban.BanService
+	badges   *badge.BadgeService
+	rights   *fuse.RightsService
+	club     *club.ClubService
+	credits  *credits.CreditsService
+	vouchers *voucher.VoucherService
+	purse    *purse.PurseService
+	logins   *LoginRepo
+	recorder *loginRecorder
+	figures  *figure.Data
+	mailer   mailer.Mailer
+	resets   *ResetRepo
+	parents  *ParentRepo
+	prefs    *PreferencesRepo
+
+	loginAttempts  *throttle
+	resetAttempts  *throttle
+	parentAttempts *throttle
+	hashing        chan struct{}
+	dummyHash      string // hash unknown usernames are checked against
+
+	mux     sync.RWMutex
+	online  map[int]*Player
+	unitIds int // last room unit ID handed out
+
 	log *zap.Logger
 }
 
-func NewPlayerService(log *zap.Logger) *PlayerService {
+func NewPlayerService(log *zap.Logger, db *sql.DB, hasher crypto.PasswordHasher, mail mailer.Mailer,
+	config *Config) *PlayerService {
 	return &PlayerService{
-		log: log,
+		config:   config,
+		hasher:   hasher,
+		bans:     ban.NewBanService(log.With(zap.String("service_name", "ban_service")), db),
+		badges:   badge.NewBadgeService(log.With(zap.String("service_name", "badge_service")), db),
+		rights:   fuse.NewRightsService(log.With(zap.String("service_name", "rights_service")), db),
+		club:     club.NewClubService(log.With(zap.String("service_name", "club_service")), db, &config.Club),
+		credits:  credits.NewCreditsService(log.With(zap.String("service_name", "credits_service")), db),
+		vouchers: voucher.NewVoucherService(log.With(zap.String("service_name", "voucher_service")), db),
+		purse:    purse.NewPurseService(log.With(zap.String("service_name", "purse_service")), db, &config.Purse),
+		logins:   NewLoginRepo(db),
+		figures:  figure.Default(),
+		mailer:   mail,
+		resets:   NewResetRepo(db),
+		parents:  NewParentRepo(db),
+		prefs:    NewPreferencesRepo(db),
+
+		loginAttempts:  newThrottle(LoginAttempts, LoginAttemptWindow),
+		resetAttempts:  newThrottle(ResetAttempts, ResetAttemptWindow),
+		parentAttempts: newThrottle(ParentEmailAttempts, ParentEmailAttemptWindow),
+		hashing:        make(chan struct{}, maxHashing),
+
+		online: make(map[int]*Player),
+		log:    log,
 	}
 }
 
 func (ps *PlayerService) Build() {
+	ps.bans.Build()
+	ps.badges.Build()
+	ps.rights.Build()
+
+	if hash, err := ps.hasher.Hash(crypto.GenerateToken(crypto.TOKENSIZE)); err == nil {
+		ps.dummyHash = hash
+	}
+
+	ps.recorder = newLoginRecorder(ps.log, ps.logins)
+	go ps.recorder.run()
+
+	if ps.config.FigureData != "" {
+		figures, err := figure.Load(ps.config.FigureData)
+		if err != nil {
+			ps.log.Warn("Failed to load figure data, falling back to the built in clothing sets",
+				zap.String("figure_data", ps.config.FigureData),
+				zap.Error(err),
+			)
+		} else {
+			ps.figures = figures
+		}
+	}
+}
+
+// Stop writes any logins & logouts still waiting to be added to the login history.
+func (ps *PlayerService) Stop() {
+	if ps.recorder != nil {
+		ps.recorder.stop()
+	}
+}
+
+// Config returns the player related game server configuration.
+func (ps *PlayerService) Config() *Config {
+	return ps.config
+}
+
+// PasswordHasher returns the crypto.PasswordHasher used to hash new passwords.
+func (ps *PlayerService) PasswordHasher() crypto.PasswordHasher {
+	return ps.hasher
+}
+
+// AllowLogin records a login attempt from the IP address and reports whether it is within LoginAttempts.
+func (ps *PlayerService) AllowLogin(address string) bool {
+	return ps.loginAttempts.allow(address)
+}
+
+// AllowPasswordReset records a password reset request from the IP address and reports whether it is within
+// ResetAttempts.
+func (ps *PlayerService) AllowPasswordReset(address string) bool {
+	return ps.resetAttempts.allow(address)
+}
+
+// VerifyPassword checks password against a stored hash like crypto.VerifyPassword, hashing at most maxHashing
+// passwords at once. An empty hash is for an unknown username, the password is still checked against a dummy hash
+// so that unknown usernames take as long to reject as wrong passwords.
+func (ps *PlayerService) VerifyPassword(encoded string, salt []byte, password string) (match, rehash bool) {
+	ps.hashing <- struct{}{}
+	defer func() { <-ps.hashing }()
+
+	if encoded == "" {
+		crypto.VerifyPassword(ps.hasher, ps.dummyHash, nil, password)
+		return false, false
+	}
+	return crypto.VerifyPassword(ps.hasher, encoded, salt, password)
+}
+
+// Bans returns the ban.BanService used to check, issue & lift bans.
+func (ps *PlayerService) Bans() *ban.BanService {
+	return ps.bans
+}
+
+// Badges returns the badge.BadgeService holding the badge catalogue.
+func (ps *PlayerService) Badges() *badge.BadgeService {
+	return ps.badges
+}
+
+// Rights returns the fuse.RightsService holding the rights of each rank.
+func (ps *PlayerService) Rights() *fuse.RightsService {
+	return ps.rights
+}
+
+// Club returns the club.ClubService selling Habbo Club memberships.
+func (ps *PlayerService) Club() *club.ClubService {
+	return ps.club
+}
+
+// Credits returns the credits.CreditsService that changes player credits.
+func (ps *PlayerService) Credits() *credits.CreditsService {
+	return ps.credits
+}
+
+// Vouchers returns the voucher.VoucherService that redeems vouchers.
+func (ps *PlayerService) Vouchers() *voucher.VoucherService {
+	return ps.vouchers
+}
+
+// ChangeCredits adds amount, which may be negative, to the credits of the player with the given id and, if they're
+// online, refreshes their balance. The online Player is returned so that they can be sent their new balance.
+func (ps *PlayerService) ChangeCredits(playerId, amount int, reason credits.Reason, reference string) (*Player, error) {
+	var (
+		balance int
+		err     error
+	)
+	if amount < 0 {
+		balance, err = ps.credits.Take(playerId, -amount, reason, reference)
+	} else {
+		balance, err = ps.credits.Give(playerId, amount, reason, reference)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := ps.OnlinePlayerById(playerId)
+	if p != nil {
+		p.Details.Credits = balance
+	}
+	return p, nil
+}
+
+// Purse returns the purse.PurseService selling tickets & film.
+func (ps *PlayerService) Purse() *purse.PurseService {
+	return ps.purse
+}
+
+// BuyPurseItem sells the buyer the bundle of the item at the given 1 based position, giving it to the player with
+// the given id. The buyer's credits & the recipient's items, if they're online, are refreshed. The online recipient
+// is returned so that they can be sent their new balance.
+func (ps *PlayerService) BuyPurseItem(buyer *Player, recipientId int, item purse.Item, choice int) (*Player, error) {
+	balance, amount, err := ps.purse.Buy(buyer.Details.Id, recipientId, item, choice)
+	if err != nil {
+		return nil, err
+	}
+	buyer.Details.Credits = balance
+
+	recipient := ps.OnlinePlayerById(recipientId)
+	if recipient != nil {
+		recipient.setPurseItem(item, amount)
+	}
+	return recipient, nil
+}
+
+// UsePurseItem takes amount of the item from the player with the given id and, if they're online, refreshes their
+// items. purse.ErrNotEnough is returned if they don't have that many.
+func (ps *PlayerService) UsePurseItem(playerId int, item purse.Item, amount int) (*Player, error) {
+	left, err := ps.purse.Use(playerId, item, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	p := ps.OnlinePlayerById(playerId)
+	if p != nil {
+		p.setPurseItem(item, left)
+	}
+	return p, nil
+}
+
+// Preferences returns the PreferencesRepo storing the settings players change for themselves.
+func (ps *PlayerService) Preferences() *PreferencesRepo {
+	return ps.prefs
+}
+
+// Logins returns the LoginRepo holding the login history.
+func (ps *PlayerService) Logins() *LoginRepo {
+	return ps.logins
+}
+
+// RecordLogin queues the player's login to be written to the login history along with their machine ID & IP address.
+func (ps *PlayerService) RecordLogin(p *Player) {
+	if ps.recorder == nil {
+		return
+	}
+	ps.recorder.enqueue(loginEvent{
+		player:    p,
+		login:     true,
+		playerId:  p.Details.Id,
+		machineId: p.MachineId,
+		address:   p.Session.Address(),
+		at:        time.Now().UTC(),
+	})
+}
+
+// RecordLogout queues the player's logout to be written to the login history, along with the session's duration.
+func (ps *PlayerService) RecordLogout(p *Player) {
+	if ps.recorder == nil {
+		return
+	}
+	ps.recorder.enqueue(loginEvent{player: p, playerId: p.Details.Id, at: time.Now().UTC()})
+}
+
+// Figures returns the figure.Data used to validate figures & list the available clothing sets.
+func (ps *PlayerService) Figures() *figure.Data {
+	return ps.figures
+}
+
+// Mailer returns the mailer.Mailer used to email players.
+func (ps *PlayerService) Mailer() mailer.Mailer {
+	return ps.mailer
+}
 
+// RequestPasswordReset emails a password reset link to the player with the given username, if email is the
+// address they registered with. Nothing is sent otherwise, so callers can't tell whether an account exists.
+func (ps *PlayerService) RequestPasswordReset(username, email string) {
+	token, err := ps.resets.CreateReset(username, email, DefaultPasswordResetLifetime)
+	if err == ErrPlayerNotFound || err == ErrResetTooSoon {
+		return
+	} else if err != nil {
+		ps.log.Warn("Failed to create password reset", zap.String("username", username), zap.Error(err))
+		return
+	}
+
+	err = ps.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Reset your habbgo password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. If it was you, "+
+			"choose a new password within the next hour at:\n\n%s?token=%s\n\n"+
+			"If it wasn't you, you can ignore this email.\n", username, ps.config.ResetURL, token),
+	})
+	if err != nil {
+		ps.log.Warn("Failed to send password reset email", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// UnderAge reports whether someone born on birthday is too young to register without COPPA restrictions.
+// Nobody is under age while COPPA is turned off.
+func (ps *PlayerService) UnderAge(birthday time.Time) bool {
+	return ps.config.Coppa > 0 && date.Age(birthday, time.Now()) < ps.config.CoppaAge
+}
+
+// AllowParentEmail records a parent confirmation email sent from the IP address and reports whether it is within
+// ParentEmailAttempts.
+func (ps *PlayerService) AllowParentEmail(address string) bool {
+	return ps.parentAttempts.allow(address)
+}
+
+// RequestParentConfirmation emails the parent of a restricted player a link that confirms their child's account.
+// ErrConfirmationPending is returned, and nothing is sent, while an earlier link can still be used.
+func (ps *PlayerService) RequestParentConfirmation(playerId int, username, parentEmail string) error {
+	token, err := ps.parents.CreateConfirmation(playerId, parentEmail, DefaultParentConfirmationLifetime)
+	if err == ErrConfirmationPending {
+		return err
+	} else if err != nil {
+		ps.log.Warn("Failed to create parent confirmation", zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	go func() {
+		err := ps.mailer.Send(mailer.Message{
+			To:      parentEmail,
+			Subject: "Please confirm your child's habbgo account",
+			Body: fmt.Sprintf("Hello,\n\nYour child has created the habbgo account %s and gave us your email address. "+
+				"Until you confirm the account they won't be able to use all of habbgo's features.\n\n"+
+				"To confirm the account, visit:\n\n%s?token=%s\n\n"+
+				"If you don't know anything about this account, you can ignore this email.\n",
+				username, ps.config.ParentConfirmURL, token),
+		})
+		if err != nil {
+			ps.log.Warn("Failed to send parent confirmation email", zap.String("username", username), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// AddOnlinePlayer marks a logged in Player as online.
+func (ps *PlayerService) AddOnlinePlayer(p *Player) {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	ps.online[p.Details.Id] = p
+}
+
+// RemoveOnlinePlayer marks a Player as offline, if the Player is still the one registered for their id.
+func (ps *PlayerService) RemoveOnlinePlayer(p *Player) {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	if ps.online[p.Details.Id] == p {
+		delete(ps.online, p.Details.Id)
+	}
+}
+
+// OnlinePlayerById returns the online Player with the given id, or nil if they aren't online.
+func (ps *PlayerService) OnlinePlayerById(id int) *Player {
+	ps.mux.RLock()
+	defer ps.mux.RUnlock()
+	return ps.online[id]
+}
+
+// OnlinePlayerByName returns the online Player with the given username, ignoring case, or nil if they aren't online.
+func (ps *PlayerService) OnlinePlayerByName(username string) *Player {
+	ps.mux.RLock()
+	defer ps.mux.RUnlock()
+	for _, p := range ps.online {
+		if strings.EqualFold(p.Details.Username, username) {
+			return p
+		}
+	}
+	return nil
+}
+
+// PlayersInRoom returns every online Player in the room with the given id.
+func (ps *PlayerService) PlayersInRoom(roomId int) []*Player {
+	ps.mux.RLock()
+	defer ps.mux.RUnlock()
+	var players []*Player
+	for _, p := range ps.online {
+		if roomId != 0 && p.RoomId == roomId {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+// EnterRoom puts the player in the room with the given id, taking them out of the room they were in, and gives them
+// a new room unit ID.
+func (ps *PlayerService) EnterRoom(p *Player, roomId int) {
+	ps.mux.Lock()
+	left := ps.enterRoom(p, roomId)
+	ps.mux.Unlock()
+
+	ps.countVisitors(p, left, roomId)
+}
+
+// EnterLetInRoom puts the player in the guest room with the given id like EnterRoom, as long as it's the room they
+// were let into, and reports whether they entered it.
+func (ps *PlayerService) EnterLetInRoom(p *Player, roomId int) bool {
+	ps.mux.Lock()
+	if roomId == 0 || roomId != p.LetInto {
+		ps.mux.Unlock()
+		return false
+	}
+	left := ps.enterRoom(p, roomId)
+	ps.mux.Unlock()
+
+	ps.countVisitors(p, left, roomId)
+	return true
+}
+
+// enterRoom must be called with ps.mux locked, it returns the id of the room the player left.
+func (ps *PlayerService) enterRoom(p *Player, roomId int) int {
+	left := p.RoomId
+	ps.unitIds++
+	p.RoomId = roomId
+	p.RoomUnitId = ps.unitIds
+	p.LetInto = 0
+	return left
+}
+
+// LetInto lets the player into the guest room with the given id, which they then enter with EnterLetInRoom.
+func (ps *PlayerService) LetInto(p *Player, roomId int) {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	p.LetInto = roomId
+}
+
+// Room returns the id of the room the player is in, 0 if they aren't in one, and their room unit ID.
+func (ps *PlayerService) Room(p *Player) (roomId, unitId int) {
+	ps.mux.RLock()
+	defer ps.mux.RUnlock()
+	return p.RoomId, p.RoomUnitId
+}
+
+// LeaveRoom takes the player out of the room they're in.
+func (ps *PlayerService) LeaveRoom(p *Player) {
+	ps.mux.Lock()
+	left := p.RoomId
+	p.RoomId = 0
+	p.RoomUnitId = 0
+	ps.mux.Unlock()
+
+	ps.countVisitors(p, left, 0)
+}
+
+// countVisitors updates the stored visitor counts of the rooms the player left & entered, 0 for neither.
+func (ps *PlayerService) countVisitors(p *Player, left, entered int) {
+	if left != 0 {
+		p.Services.RoomService().RemoveVisitor(left)
+	}
+	if entered != 0 {
+		p.Services.RoomService().AddVisitor(entered)
+	}
+}
+
+// OnlinePlayers returns every online Player.
+func (ps *PlayerService) OnlinePlayers() []*Player {
+	ps.mux.RLock()
+	defer ps.mux.RUnlock()
+	players := make([]*Player, 0, len(ps.online))
+	for _, p := range ps.online {
+		players = append(players, p)
+	}
+	return players
 }
@@ -0,0 +1,58 @@
+package player
+
+import (
+	"database/sql"
+)
+
+// Preferences are the settings a player can change for themselves.
+type Preferences struct {
+	SoundEnabled         bool
+	DirectMail           bool // the player agreed to receive emails about the hotel
+	AcceptFriendRequests bool // other players can ask to be the player's friend
+}
+
+// PreferencesRepo stores player Preferences in the player_preferences table. Players without a row use the
+// configured defaults.
+type PreferencesRepo struct {
+	database *sql.DB
+}
+
+// NewPreferencesRepo returns a new instance of PreferencesRepo.
+func NewPreferencesRepo(db *sql.DB) *PreferencesRepo {
+	return &PreferencesRepo{database: db}
+}
+
+// Load returns the player's Preferences, or a copy of defaults if they have never changed them.
+func (pr *PreferencesRepo) Load(playerId int, defaults Preferences) (*Preferences, error) {
+	prefs := defaults
+	err := pr.database.QueryRow(
+		"SELECT sound_enabled, direct_mail, accept_friend_requests FROM player_preferences WHERE player_id = $1",
+		playerId).Scan(&prefs.SoundEnabled, &prefs.DirectMail, &prefs.AcceptFriendRequests)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return &prefs, nil
+	case err != nil:
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// Save stores the player's Preferences.
+func (pr *PreferencesRepo) Save(playerId int, prefs *Preferences) error {
+	return savePreferences(pr.database, playerId, prefs)
+}
+
+// execer is implemented by both sql.DB & sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func savePreferences(db execer, playerId int, prefs *Preferences) error {
+	_, err := db.Exec(
+		"INSERT INTO player_preferences(player_id, sound_enabled, direct_mail, accept_friend_requests) "+
+			"VALUES($1, $2, $3, $4) ON CONFLICT (player_id) DO UPDATE SET sound_enabled = $2, "+
+			"direct_mail = $3, accept_friend_requests = $4",
+		playerId, prefs.SoundEnabled, prefs.DirectMail, prefs.AcceptFriendRequests)
+	return err
+}
@@ -0,0 +1,129 @@
+package player
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+)
+
+const (
+	DefaultPasswordResetLifetime = time.Hour       // how long a password reset link can be used
+	PasswordResetInterval        = 5 * time.Minute // how long a player waits before another reset link is sent
+)
+
+var (
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+	ErrResetTooSoon      = errors.New("a password reset was requested too recently")
+)
+
+// ResetRepo creates and consumes the single-use password reset tokens stored in the password_resets table.
+type ResetRepo struct {
+	database *sql.DB
+}
+
+// NewResetRepo returns a new instance of ResetRepo.
+func NewResetRepo(db *sql.DB) *ResetRepo {
+	return &ResetRepo{database: db}
+}
+
+// CreateReset creates a reset token for the player with the given username, as long as email is the address they
+// registered with, and returns the token. Any tokens the player already had are replaced, unless one was created less
+// than PasswordResetInterval ago and is still valid, in which case ErrResetTooSoon is returned.
+func (rr *ResetRepo) CreateReset(username, email string, lifetime time.Duration) (string, error) {
+	tx, err := rr.database.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow("SELECT P.id FROM players P WHERE P.username = $1 AND lower(P.email) = lower($2) FOR UPDATE",
+		username, email).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", ErrPlayerNotFound
+	case err != nil:
+		return "", err
+	}
+
+	var recent bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM password_resets WHERE player_id = $1 AND expires_at > $2 "+
+		"AND created_at > current_timestamp - make_interval(secs => $3))",
+		id, time.Now().UTC(), PasswordResetInterval.Seconds()).Scan(&recent)
+	if err != nil {
+		return "", err
+	}
+	if recent {
+		return "", ErrResetTooSoon
+	}
+
+	if _, err := tx.Exec("DELETE FROM password_resets WHERE player_id = $1", id); err != nil {
+		return "", err
+	}
+
+	token := crypto.GenerateToken(crypto.TOKENSIZE)
+	_, err = tx.Exec("INSERT INTO password_resets(token, player_id, expires_at) VALUES($1, $2, $3)",
+		token, id, time.Now().UTC().Add(lifetime))
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// ResetUsername returns the username of the player the reset token was created for, without burning it, or
+// ErrInvalidResetToken if it doesn't exist or has expired.
+func (rr *ResetRepo) ResetUsername(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidResetToken
+	}
+
+	var username string
+	err := rr.database.QueryRow("SELECT P.username FROM password_resets R JOIN players P ON P.id = R.player_id "+
+		"WHERE R.token = $1 AND R.expires_at > $2", token, time.Now().UTC()).Scan(&username)
+	if err == sql.ErrNoRows {
+		return "", ErrInvalidResetToken
+	}
+	return username, err
+}
+
+// ResetPassword burns the reset token and replaces the password hash of the player it was created for.
+// The token is burnt even when it has expired so that it can never be tried twice.
+func (rr *ResetRepo) ResetPassword(token, passwordHash string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+
+	tx, err := rr.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		id        int
+		expiresAt time.Time
+	)
+	err = tx.QueryRow("DELETE FROM password_resets WHERE token = $1 RETURNING player_id, expires_at", token).
+		Scan(&id, &expiresAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrInvalidResetToken
+	case err != nil:
+		return err
+	case time.Now().UTC().After(expiresAt):
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return ErrInvalidResetToken
+	}
+
+	_, err = tx.Exec("UPDATE players SET password_hash = $1, password_salt = '' WHERE id = $2", passwordHash, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
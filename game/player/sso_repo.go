@@ -0,0 +1,89 @@
+package player
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+)
+
+// DefaultSSOTicketLifetime is how long a freshly minted SSO ticket can be used to log in.
+const DefaultSSOTicketLifetime = 5 * time.Minute
+
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrNameTaken      = errors.New("username is already taken")
+	ErrInvalidTicket  = errors.New("sso ticket is invalid, expired or bound to another address")
+	ErrUnboundTicket  = errors.New("sso tickets must be bound to an ip address")
+)
+
+// SSORepo mints and consumes the single-use SSO tickets stored in the players.sso_token column.
+type SSORepo struct {
+	database *sql.DB
+}
+
+// NewSSORepo returns a new instance of SSORepo.
+func NewSSORepo(db *sql.DB) *SSORepo {
+	return &SSORepo{database: db}
+}
+
+// MintTicket generates a new SSO ticket for the player with the given username, replacing any ticket they already
+// had. The ticket expires after lifetime and can only be used from the given IP address.
+func (sr *SSORepo) MintTicket(username, ip string, lifetime time.Duration) (string, error) {
+	if ip == "" {
+		return "", ErrUnboundTicket
+	}
+
+	ticket := crypto.GenerateToken(crypto.TOKENSIZE)
+	expiresAt := time.Now().UTC().Add(lifetime)
+
+	res, err := sr.database.Exec(
+		"UPDATE players SET sso_token = $1, sso_ip = $2, sso_expires_at = $3 WHERE username = $4",
+		ticket, ip, expiresAt, username)
+	if err != nil {
+		return "", err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 0 {
+		return "", ErrPlayerNotFound
+	}
+
+	return ticket, nil
+}
+
+// ConsumeTicket burns the given SSO ticket and returns the username of the player it was minted for.
+// The ticket is cleared even when it turns out to be expired or used from the wrong IP address,
+// so a ticket can never be tried twice.
+func (sr *SSORepo) ConsumeTicket(ticket, ip string) (string, error) {
+	if ticket == "" {
+		return "", ErrInvalidTicket
+	}
+
+	var (
+		username  string
+		boundIp   sql.NullString
+		expiresAt sql.NullTime
+	)
+
+	err := sr.database.QueryRow(
+		"WITH T AS (SELECT id, username, sso_ip, sso_expires_at FROM players WHERE sso_token = $1 FOR UPDATE) "+
+			"UPDATE players P SET sso_token = NULL, sso_ip = NULL, sso_expires_at = NULL FROM T WHERE P.id = T.id "+
+			"RETURNING T.username, T.sso_ip, T.sso_expires_at", ticket).
+		Scan(&username, &boundIp, &expiresAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return "", ErrInvalidTicket
+	case err != nil:
+		return "", err
+	case !expiresAt.Valid || time.Now().UTC().After(expiresAt.Time):
+		return "", ErrInvalidTicket
+	case !boundIp.Valid || boundIp.String == "" || boundIp.String != ip:
+		return "", ErrInvalidTicket
+	}
+
+	return username, nil
+}
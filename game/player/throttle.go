@@ -0,0 +1,58 @@
+package player
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle limits how many attempts each key, such as an IP address or username, can make within a sliding window.
+type throttle struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mux      sync.Mutex
+	attempts map[string][]time.Time
+	swept    time.Time
+}
+
+func newThrottle(limit int, window time.Duration) *throttle {
+	return &throttle{
+		limit:    limit,
+		window:   window,
+		now:      time.Now,
+		attempts: make(map[string][]time.Time),
+	}
+}
+
+// allow records an attempt for key and reports whether it is within the limit. Refused attempts aren't recorded,
+// so a key is let through again as soon as its oldest attempt leaves the window.
+func (t *throttle) allow(key string) bool {
+	now := t.now()
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if now.Sub(t.swept) >= t.window {
+		for k, attempts := range t.attempts {
+			if now.Sub(attempts[len(attempts)-1]) >= t.window {
+				delete(t.attempts, k)
+			}
+		}
+		t.swept = now
+	}
+
+	var recent []time.Time
+	for _, at := range t.attempts[key] {
+		if now.Sub(at) < t.window {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) >= t.limit {
+		t.attempts[key] = recent
+		return false
+	}
+	t.attempts[key] = append(recent, now)
+	return true
+}
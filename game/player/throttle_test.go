@@ -0,0 +1,26 @@
+package player
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2021, 11, 20, 12, 0, 0, 0, time.UTC)
+	th := newThrottle(2, time.Minute)
+	th.now = func() time.Time { return now }
+
+	require.True(t, th.allow("127.0.0.1"))
+	require.True(t, th.allow("127.0.0.1"))
+	require.False(t, th.allow("127.0.0.1"))
+	require.True(t, th.allow("10.0.0.1"))
+
+	now = now.Add(59 * time.Second)
+	require.False(t, th.allow("127.0.0.1"))
+
+	now = now.Add(time.Second)
+	require.True(t, th.allow("127.0.0.1"))
+	require.Len(t, th.attempts, 1)
+}
@@ -0,0 +1,51 @@
+package purse
+
+import "errors"
+
+var (
+	ErrNotEnough     = errors.New("not enough tickets or film")
+	ErrUnknownBundle = errors.New("unknown bundle")
+)
+
+// Item is something other than credits players keep in their purse. Its value is the players column holding it.
+type Item string
+
+const (
+	Tickets Item = "tickets" // spent to play games such as the diving board & Battle Ball
+	Film    Item = "film"    // spent to take photos with the camera
+)
+
+// Bundle is an amount of an Item sold together for credits.
+type Bundle struct {
+	Amount   int
+	Credits  int
+	SaleCode string // catalogue sale code film bundles are bought with
+}
+
+// Config is the price list of tickets & film.
+type Config struct {
+	Tickets []Bundle // chosen by the client with their 1 based position
+	Film    []Bundle // chosen by the client with their 1 based position
+}
+
+// bundle returns the bundle of the item at the given 1 based position.
+func (c *Config) bundle(item Item, choice int) (Bundle, error) {
+	bundles := c.Tickets
+	if item == Film {
+		bundles = c.Film
+	}
+	if choice < 1 || choice > len(bundles) {
+		return Bundle{}, ErrUnknownBundle
+	}
+	return bundles[choice-1], nil
+}
+
+// FilmChoice returns the 1 based position of the film bundle sold in the catalogue with the given sale code.
+func (c *Config) FilmChoice(saleCode string) (int, bool) {
+	for i, b := range c.Film {
+		if b.SaleCode != "" && b.SaleCode == saleCode {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package purse
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jtieri/habbgo/game/credits"
+)
+
+type PurseRepo struct {
+	database *sql.DB
+}
+
+// NewPurseRepo returns a new instance of PurseRepo.
+func NewPurseRepo(db *sql.DB) *PurseRepo {
+	return &PurseRepo{database: db}
+}
+
+// Buy takes the bundle's price from the buyer's credits & gives its items to the recipient, who may be the buyer.
+// credits.ErrNoCredits is returned if the buyer can't afford it. The buyer's credits & the recipient's amount of the
+// item afterwards are returned.
+func (pr *PurseRepo) Buy(buyerId, recipientId int, item Item, bundle Bundle) (int, int, error) {
+	tx, err := pr.database.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	reference := fmt.Sprintf("%d %s", bundle.Amount, item)
+	if recipientId != buyerId {
+		reference += fmt.Sprintf(" for player %d", recipientId)
+	}
+
+	balance, err := credits.Change(tx, buyerId, -bundle.Credits, credits.Purse, reference)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	amount, err := change(tx, recipientId, item, bundle.Amount)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return balance, amount, tx.Commit()
+}
+
+// Use takes amount of the item from the player, returning how many they have left. ErrNotEnough is returned if they
+// don't have that many.
+func (pr *PurseRepo) Use(playerId int, item Item, amount int) (int, error) {
+	tx, err := pr.database.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	left, err := change(tx, playerId, item, -amount)
+	if err != nil {
+		return 0, err
+	}
+	return left, tx.Commit()
+}
+
+func change(tx *sql.Tx, playerId int, item Item, amount int) (int, error) {
+	if item != Tickets && item != Film {
+		return 0, fmt.Errorf("unknown purse item %q", item)
+	}
+
+	var total int
+	err := tx.QueryRow(fmt.Sprintf("UPDATE players SET %[1]s = %[1]s + $1 WHERE id = $2 AND %[1]s + $1 >= 0 "+
+		"RETURNING %[1]s", item), amount, playerId).Scan(&total)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotEnough
+	}
+	return total, err
+}
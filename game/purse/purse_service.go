@@ -0,0 +1,48 @@
+package purse
+
+import (
+	"database/sql"
+
+	"go.uber.org/zap"
+)
+
+// PurseService sells tickets & film for credits and takes them when they're used.
+type PurseService struct {
+	repo   *PurseRepo
+	config *Config
+
+	log *zap.Logger
+}
+
+func NewPurseService(log *zap.Logger, db *sql.DB, config *Config) *PurseService {
+	return &PurseService{
+		repo:   NewPurseRepo(db),
+		config: config,
+		log:    log,
+	}
+}
+
+// Buy sells the buyer the bundle of the item at the given 1 based position, giving it to the recipient.
+// The buyer's credits & the recipient's amount of the item afterwards are returned.
+func (ps *PurseService) Buy(buyerId, recipientId int, item Item, choice int) (int, int, error) {
+	bundle, err := ps.config.bundle(item, choice)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ps.repo.Buy(buyerId, recipientId, item, bundle)
+}
+
+// Use takes amount of the item from the player, returning how many they have left. ErrNotEnough is returned if they
+// don't have that many. Games call this before letting a player play.
+func (ps *PurseService) Use(playerId int, item Item, amount int) (int, error) {
+	left, err := ps.repo.Use(playerId, item, amount)
+	if err != nil && err != ErrNotEnough {
+		ps.log.Warn("Failed to use purse item",
+			zap.Int("player_id", playerId),
+			zap.String("item", string(item)),
+			zap.Int("amount", amount),
+			zap.Error(err),
+		)
+	}
+	return left, err
+}
@@ -0,0 +1,40 @@
+package purse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	config := &Config{
+		Tickets: []Bundle{{Amount: 2, Credits: 1}, {Amount: 20, Credits: 6}},
+		Film:    []Bundle{{Amount: 5, Credits: 6}},
+	}
+
+	b, err := config.bundle(Tickets, 2)
+	require.NoError(t, err)
+	require.Equal(t, Bundle{Amount: 20, Credits: 6}, b)
+
+	b, err = config.bundle(Film, 1)
+	require.NoError(t, err)
+	require.Equal(t, 5, b.Amount)
+
+	_, err = config.bundle(Film, 2)
+	require.Equal(t, ErrUnknownBundle, err)
+	_, err = config.bundle(Tickets, 0)
+	require.Equal(t, ErrUnknownBundle, err)
+}
+
+func TestFilmChoice(t *testing.T) {
+	config := &Config{Film: []Bundle{{Amount: 5, Credits: 6}, {Amount: 10, Credits: 10, SaleCode: "film10"}}}
+
+	choice, ok := config.FilmChoice("film10")
+	require.True(t, ok)
+	require.Equal(t, 2, choice)
+
+	_, ok = config.FilmChoice("")
+	require.False(t, ok)
+	_, ok = config.FilmChoice("throne")
+	require.False(t, ok)
+}
@@ -0,0 +1,81 @@
+package room
+
+import (
+	"database/sql"
+	"errors"
+)
+
+var ErrTooManyFavourites = errors.New("player has too many favourite rooms")
+
+// FavouriteRepo stores the rooms players added to their favourites in the room_favourites table.
+type FavouriteRepo struct {
+	database *sql.DB
+}
+
+// NewFavouriteRepo returns a new instance of FavouriteRepo.
+func NewFavouriteRepo(db *sql.DB) *FavouriteRepo {
+	return &FavouriteRepo{database: db}
+}
+
+// Favourites returns the player's favourite rooms, in the order they were added.
+func (fr *FavouriteRepo) Favourites(playerId int) ([]*Room, error) {
+	rows, err := fr.database.Query(roomQuery+" JOIN room_favourites F ON F.room_id = R.id "+
+		"WHERE F.player_id = $1 ORDER BY F.added_at, R.id", playerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRooms(rows), rows.Err()
+}
+
+// Add adds the room to the player's favourites unless they already have max favourites, in which case
+// ErrTooManyFavourites is returned. Adding a room that is already a favourite does nothing.
+func (fr *FavouriteRepo) Add(playerId, roomId, max int) error {
+	tx, err := fr.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPlayer(tx, playerId); err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM room_favourites WHERE player_id = $1 AND room_id = $2)",
+		playerId, roomId).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM room_favourites WHERE player_id = $1", playerId).
+		Scan(&count); err != nil {
+		return err
+	}
+	if count >= max {
+		return ErrTooManyFavourites
+	}
+
+	_, err = tx.Exec("INSERT INTO room_favourites(player_id, room_id) VALUES($1, $2)", playerId, roomId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Remove removes the room from the player's favourites.
+func (fr *FavouriteRepo) Remove(playerId, roomId int) error {
+	_, err := fr.database.Exec("DELETE FROM room_favourites WHERE player_id = $1 AND room_id = $2", playerId, roomId)
+	return err
+}
+
+// lockPlayer locks the player's row until tx ends, so that concurrent transactions checking one of the player's
+// limits can't both squeeze under it.
+func lockPlayer(tx *sql.Tx, playerId int) error {
+	_, err := tx.Exec("SELECT 1 FROM players WHERE id = $1 FOR UPDATE", playerId)
+	return err
+}
@@ -1,6 +1,9 @@
 package room
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Room struct {
 	Details *Details
@@ -38,6 +41,39 @@ func NewRoom() *Room {
 	}
 }
 
+const (
+	// DefaultCategoryId is the "No category" category new guest rooms are put in.
+	DefaultCategoryId = 2
+	// DefaultMaxVisitors is how many visitors new guest rooms allow.
+	DefaultMaxVisitors = 25
+)
+
+// GuestModel reports whether players can create guest rooms with the model, public room models are off limits.
+func GuestModel(name string) bool {
+	return strings.HasPrefix(name, "model_")
+}
+
+// specialModels are the guest room layouts only club members can use.
+var specialModels = map[string]bool{
+	"model_g": true,
+	"model_h": true,
+	"model_i": true,
+	"model_j": true,
+	"model_k": true,
+	"model_l": true,
+	"model_m": true,
+	"model_n": true,
+	"model_o": true,
+	"model_p": true,
+	"model_q": true,
+	"model_r": true,
+}
+
+// SpecialModel reports whether the guest room model is one of the special layouts only club members can use.
+func SpecialModel(name string) bool {
+	return specialModels[name]
+}
+
 type Access int
 
 const (
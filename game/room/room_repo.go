@@ -2,9 +2,20 @@ package room
 
 import (
 	"database/sql"
+	"errors"
 	"log"
+	"strings"
+	"time"
 )
 
+var ErrTooManyRooms = errors.New("player owns too many rooms")
+
+// roomQuery selects rooms along with their owner's & model's names, in the column order scanRooms expects.
+const roomQuery = "SELECT R.id, R.category_id, R.name, R.description, R.owner_id, COALESCE(P.username, ''), " +
+	"R.model_id, COALESCE(M.name, ''), R.ccts, R.wallpaper, R.floor, R.show_name, R.password, R.access, R.sudo_users, R.current_visitors, " +
+	"R.max_visitors, R.rating, R.hidden, R.created_at, R.updated_at " +
+	"FROM rooms R LEFT JOIN players P ON P.id = R.owner_id LEFT JOIN room_models M ON M.id = R.model_id"
+
 type RoomRepo struct {
 	database *sql.DB
 }
@@ -13,30 +24,125 @@ func NewRoomRepo(db *sql.DB) *RoomRepo {
 	return &RoomRepo{database: db}
 }
 
+// RoomById returns the room with the given id, or nil if it doesn't exist.
+func (rr *RoomRepo) RoomById(id int) *Room {
+	rows, err := rr.database.Query(roomQuery+" WHERE R.id = $1", id)
+	if err != nil {
+		log.Printf("%v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	rooms := scanRooms(rows)
+	if len(rooms) == 0 {
+		return nil
+	}
+	return rooms[0]
+}
+
 func (rr *RoomRepo) RoomsByPlayerId(id int) []*Room {
-	stmt, err := rr.database.Prepare("SELECT * FROM rooms WHERE owner_id = $1")
+	rows, err := rr.database.Query(roomQuery+" WHERE R.owner_id = $1", id)
+	if err != nil {
+		log.Printf("%v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	return scanRooms(rows)
+}
+
+// PrivateRoomsByCategoryId returns up to limit player owned rooms in the category, the busiest rooms first.
+func (rr *RoomRepo) PrivateRoomsByCategoryId(categoryId, limit int) []*Room {
+	rows, err := rr.database.Query(roomQuery+" WHERE R.category_id = $1 AND R.owner_id != 0 "+
+		"ORDER BY R.current_visitors DESC, R.id LIMIT $2", categoryId, limit)
+	if err != nil {
+		log.Printf("%v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	return scanRooms(rows)
+}
+
+// RoomsByOwnerName returns the rooms owned by the player with the given username, ignoring case.
+func (rr *RoomRepo) RoomsByOwnerName(username string) []*Room {
+	rows, err := rr.database.Query(roomQuery+" WHERE LOWER(P.username) = LOWER($1) ORDER BY R.id", username)
 	if err != nil {
 		log.Printf("%v", err)
+		return nil
 	}
-	defer stmt.Close()
+	defer rows.Close()
+
+	return scanRooms(rows)
+}
+
+// SearchRooms returns up to limit rooms that aren't hidden whose name starts with query or whose owner is called
+// query, ignoring case. The busiest rooms come first.
+func (rr *RoomRepo) SearchRooms(query string, limit int) []*Room {
+	rows, err := rr.database.Query(roomQuery+" WHERE R.owner_id != 0 AND NOT R.hidden AND "+
+		"(LOWER(R.name) LIKE $1 ESCAPE '\\' OR LOWER(P.username) = LOWER($2)) "+
+		"ORDER BY R.current_visitors DESC, R.id LIMIT $3", likePrefix(query), query, limit)
+	if err != nil {
+		log.Printf("%v", err)
+		return nil
+	}
+	defer rows.Close()
 
-	rows, err := stmt.Query(id)
+	return scanRooms(rows)
+}
+
+// BusyRooms returns up to limit player owned rooms that aren't hidden and have visitors, the busiest rooms first.
+func (rr *RoomRepo) BusyRooms(limit int) []*Room {
+	rows, err := rr.database.Query(roomQuery+" WHERE R.owner_id != 0 AND NOT R.hidden AND R.current_visitors > 0 "+
+		"ORDER BY R.current_visitors DESC, R.id LIMIT $1", limit)
 	if err != nil {
 		log.Printf("%v", err)
+		return nil
 	}
 	defer rows.Close()
 
+	return scanRooms(rows)
+}
+
+// CategoryVisitors returns the total visitors & maximum visitors of the rooms in the category that aren't hidden.
+func (rr *RoomRepo) CategoryVisitors(categoryId int) (current, max int, err error) {
+	err = rr.database.QueryRow("SELECT COALESCE(SUM(R.current_visitors), 0), COALESCE(SUM(R.max_visitors), 0) "+
+		"FROM rooms R WHERE R.category_id = $1 AND NOT R.hidden", categoryId).Scan(&current, &max)
+	return current, max, err
+}
+
+// AddVisitors changes the stored visitor count of the room by delta, never going below zero.
+func (rr *RoomRepo) AddVisitors(roomId, delta int) error {
+	_, err := rr.database.Exec("UPDATE rooms SET current_visitors = GREATEST(current_visitors + $2, 0) WHERE id = $1",
+		roomId, delta)
+	return err
+}
+
+// ResetVisitors sets the stored visitor count of every room to zero.
+func (rr *RoomRepo) ResetVisitors() error {
+	_, err := rr.database.Exec("UPDATE rooms SET current_visitors = 0 WHERE current_visitors != 0")
+	return err
+}
+
+// likePrefix returns a LIKE pattern matching lower case strings that start with s.
+func likePrefix(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(s))
+	return s + "%"
+}
+
+func scanRooms(rows *sql.Rows) []*Room {
 	var rooms []*Room
 	for rows.Next() {
 		r := NewRoom()
 
 		var tmpAccessType string
 		err := rows.Scan(&r.Details.Id, &r.Details.CategoryID, &r.Details.Name, &r.Details.Description, &r.Details.OwnerId,
-			&r.Model.ID, &r.Details.CCTs, &r.Details.Wallpaper, &r.Details.Floor, &r.Details.ShowOwner, &r.Details.Password,
-			&tmpAccessType, &r.Details.SudoUsers, &r.Details.CurrentVisitors, &r.Details.MaxVisitors, &r.Details.Rating,
-			&r.Details.Hidden, &r.Details.CreatedAt, &r.Details.UpdatedAt)
+			&r.Details.OwnerName, &r.Model.ID, &r.Model.Name, &r.Details.CCTs, &r.Details.Wallpaper, &r.Details.Floor,
+			&r.Details.ShowOwner, &r.Details.Password, &tmpAccessType, &r.Details.SudoUsers, &r.Details.CurrentVisitors,
+			&r.Details.MaxVisitors, &r.Details.Rating, &r.Details.Hidden, &r.Details.CreatedAt, &r.Details.UpdatedAt)
 		if err != nil {
 			log.Printf("%v", err)
+			continue
 		}
 
 		r.Details.AccessType = AccessType(tmpAccessType)
@@ -47,6 +153,70 @@ func (rr *RoomRepo) RoomsByPlayerId(id int) []*Room {
 	return rooms
 }
 
+// ModelByName returns the room model with the given name, or nil if it doesn't exist.
+func (rr *RoomRepo) ModelByName(name string) (*Model, error) {
+	m := &Model{}
+	err := rr.database.QueryRow("SELECT M.id, M.name, M.door_x, M.door_y, M.door_z, M.door_dir, M.heightmap "+
+		"FROM room_models M WHERE M.name = $1", name).
+		Scan(&m.ID, &m.Name, &m.DoorX, &m.DoorY, &m.DoorZ, &m.DoorDirection, &m.Heightmap)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+// CreateRoom stores a new room owned by the player in r.Details.OwnerId, unless they already own max rooms in which
+// case ErrTooManyRooms is returned. The new room's id is set on r.
+func (rr *RoomRepo) CreateRoom(r *Room, max int) error {
+	tx, err := rr.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPlayer(tx, r.Details.OwnerId); err != nil {
+		return err
+	}
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM rooms WHERE owner_id = $1", r.Details.OwnerId).Scan(&count); err != nil {
+		return err
+	}
+	if count >= max {
+		return ErrTooManyRooms
+	}
+
+	now := time.Now().UTC()
+	err = tx.QueryRow("INSERT INTO rooms(category_id, name, description, owner_id, model_id, show_name, access, "+
+		"password, max_visitors, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10) "+
+		"RETURNING id", r.Details.CategoryID, r.Details.Name, r.Details.Description, r.Details.OwnerId, r.Model.ID,
+		r.Details.ShowOwner, r.Details.AccessType.String(), r.Details.Password, r.Details.MaxVisitors, now).
+		Scan(&r.Details.Id)
+	if err != nil {
+		return err
+	}
+	r.Details.CreatedAt, r.Details.UpdatedAt = now, now
+
+	return tx.Commit()
+}
+
+// UpdateRoom stores the settings the owner can change.
+func (rr *RoomRepo) UpdateRoom(r *Room) error {
+	r.Details.UpdatedAt = time.Now().UTC()
+	_, err := rr.database.Exec("UPDATE rooms SET category_id = $2, name = $3, description = $4, show_name = $5, "+
+		"access = $6, password = $7, sudo_users = $8, max_visitors = $9, updated_at = $10 WHERE id = $1",
+		r.Details.Id, r.Details.CategoryID, r.Details.Name, r.Details.Description, r.Details.ShowOwner,
+		r.Details.AccessType.String(), r.Details.Password, r.Details.SudoUsers, r.Details.MaxVisitors,
+		r.Details.UpdatedAt)
+	return err
+}
+
+// DeleteRoom deletes the room with the given id.
+func (rr *RoomRepo) DeleteRoom(id int) error {
+	_, err := rr.database.Exec("DELETE FROM rooms WHERE id = $1", id)
+	return err
+}
+
 func (rr *RoomRepo) fillData(data *Details) {
 
 }
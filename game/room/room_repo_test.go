@@ -0,0 +1,14 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikePrefix(t *testing.T) {
+	require.Equal(t, "lounge%", likePrefix("Lounge"))
+	require.Equal(t, `100\% fun\_room%`, likePrefix("100% Fun_Room"))
+	require.Equal(t, `a\\b%`, likePrefix(`a\b`))
+	require.Equal(t, "%", likePrefix(""))
+}
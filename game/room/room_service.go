@@ -10,22 +10,27 @@ import (
 const PublicRoomOffset = 1000
 
 type RoomService struct {
-	repo  *RoomRepo
-	rooms map[int]*Room
+	repo       *RoomRepo
+	favourites *FavouriteRepo
+	rooms      map[int]*Room
 
 	log *zap.Logger
 }
 
 func NewRoomService(log *zap.Logger, db *sql.DB) *RoomService {
 	return &RoomService{
-		repo:  NewRoomRepo(db),
-		rooms: nil,
-		log:   log,
+		repo:       NewRoomRepo(db),
+		favourites: NewFavouriteRepo(db),
+		rooms:      nil,
+		log:        log,
 	}
 }
 
+// Build clears the visitor counts left behind by the last run, nobody is in a room until players log in.
 func (rs *RoomService) Build() {
-
+	if err := rs.repo.ResetVisitors(); err != nil {
+		rs.log.Warn("Failed to reset room visitor counts", zap.Error(err))
+	}
 }
 
 func (rs *RoomService) Rooms() []*Room {
@@ -43,10 +48,140 @@ func (rs *RoomService) RoomById(id int) *Room {
 	return nil
 }
 
+// LoadRoom returns the room with the given id, loaded or not, or nil if it doesn't exist.
+func (rs *RoomService) LoadRoom(id int) *Room {
+	if room := rs.RoomById(id); room != nil {
+		return room
+	}
+	return rs.repo.RoomById(id)
+}
+
 func (rs *RoomService) RoomsByPlayerId(id int) []*Room {
 	return rs.repo.RoomsByPlayerId(id)
 }
 
+// PrivateRoomsByCategoryId returns up to limit player owned rooms in the category, the busiest rooms first.
+func (rs *RoomService) PrivateRoomsByCategoryId(categoryId, limit int) []*Room {
+	return rs.ReplaceRooms(rs.repo.PrivateRoomsByCategoryId(categoryId, limit))
+}
+
+// RoomsByOwnerName returns the rooms owned by the player with the given username, ignoring case.
+func (rs *RoomService) RoomsByOwnerName(username string) []*Room {
+	return rs.ReplaceRooms(rs.repo.RoomsByOwnerName(username))
+}
+
+// SearchRooms returns up to limit rooms that aren't hidden whose name starts with query or whose owner is called
+// query, ignoring case. The busiest rooms come first.
+func (rs *RoomService) SearchRooms(query string, limit int) []*Room {
+	return rs.ReplaceRooms(rs.repo.SearchRooms(query, limit))
+}
+
+// PopularRooms returns up to limit player owned rooms that aren't hidden and have visitors, the busiest rooms first.
+func (rs *RoomService) PopularRooms(limit int) []*Room {
+	return rs.ReplaceRooms(rs.repo.BusyRooms(limit))
+}
+
+// CategoryVisitors returns the total visitors & maximum visitors of the rooms in the category that aren't hidden.
+func (rs *RoomService) CategoryVisitors(categoryId int) (current, max int) {
+	current, max, err := rs.repo.CategoryVisitors(categoryId)
+	if err != nil {
+		rs.log.Warn("Failed to count category visitors", zap.Int("category_id", categoryId), zap.Error(err))
+	}
+	return current, max
+}
+
+// AddVisitor counts a player entering the room in its visitor count.
+func (rs *RoomService) AddVisitor(roomId int) {
+	rs.addVisitors(roomId, 1)
+}
+
+// RemoveVisitor takes a player leaving the room off its visitor count.
+func (rs *RoomService) RemoveVisitor(roomId int) {
+	rs.addVisitors(roomId, -1)
+}
+
+func (rs *RoomService) addVisitors(roomId, delta int) {
+	if err := rs.repo.AddVisitors(roomId, delta); err != nil {
+		rs.log.Warn("Failed to update room visitor count", zap.Int("room_id", roomId), zap.Error(err))
+	}
+}
+
+// Favourites returns the player's favourite rooms, their visitor counts are kept up to date by AddVisitor & RemoveVisitor.
+func (rs *RoomService) Favourites(playerId int) []*Room {
+	rooms, err := rs.favourites.Favourites(playerId)
+	if err != nil {
+		rs.log.Warn("Failed to load favourite rooms", zap.Int("player_id", playerId), zap.Error(err))
+		return nil
+	}
+	return rs.ReplaceRooms(rooms)
+}
+
+// AddFavourite adds the room to the player's favourites, ErrTooManyFavourites is returned if they already have max.
+func (rs *RoomService) AddFavourite(playerId, roomId, max int) error {
+	err := rs.favourites.Add(playerId, roomId, max)
+	if err != nil && err != ErrTooManyFavourites {
+		rs.log.Warn("Failed to add favourite room",
+			zap.Int("player_id", playerId),
+			zap.Int("room_id", roomId),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// RemoveFavourite removes the room from the player's favourites.
+func (rs *RoomService) RemoveFavourite(playerId, roomId int) {
+	if err := rs.favourites.Remove(playerId, roomId); err != nil {
+		rs.log.Warn("Failed to remove favourite room",
+			zap.Int("player_id", playerId),
+			zap.Int("room_id", roomId),
+			zap.Error(err),
+		)
+	}
+}
+
+// Model returns the room model with the given name, or nil if it doesn't exist.
+func (rs *RoomService) Model(name string) *Model {
+	m, err := rs.repo.ModelByName(name)
+	if err != nil {
+		rs.log.Warn("Failed to load room model", zap.String("model", name), zap.Error(err))
+		return nil
+	}
+	return m
+}
+
+// CreateRoom stores a new guest room, ErrTooManyRooms is returned if its owner already owns max rooms.
+func (rs *RoomService) CreateRoom(r *Room, max int) error {
+	err := rs.repo.CreateRoom(r, max)
+	if err != nil && err != ErrTooManyRooms {
+		rs.log.Warn("Failed to create room",
+			zap.Int("owner_id", r.Details.OwnerId),
+			zap.String("name", r.Details.Name),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// SaveRoom stores the room's settings after they've been changed.
+func (rs *RoomService) SaveRoom(r *Room) error {
+	err := rs.repo.UpdateRoom(r)
+	if err != nil {
+		rs.log.Warn("Failed to save room", zap.Int("room_id", r.Details.Id), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteRoom deletes the room and unloads it.
+func (rs *RoomService) DeleteRoom(r *Room) error {
+	if err := rs.repo.DeleteRoom(r.Details.Id); err != nil {
+		rs.log.Warn("Failed to delete room", zap.Int("room_id", r.Details.Id), zap.Error(err))
+		return err
+	}
+	delete(rs.rooms, r.Details.Id)
+	return nil
+}
+
 func (rs *RoomService) RoomByModelName(name string) *Room {
 	return &Room{}
 }
@@ -0,0 +1,75 @@
+package voucher
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid          = errors.New("voucher code doesn't exist")
+	ErrExpired          = errors.New("voucher has expired")
+	ErrUsedUp           = errors.New("voucher has been used up")
+	ErrAlreadyRedeemed  = errors.New("voucher was already redeemed by the player")
+	ErrNothingToRedeem  = errors.New("voucher gives no credits or items")
+	ErrDuplicateVoucher = errors.New("voucher code already exists")
+	ErrInvalidCount     = errors.New("at least one voucher must be generated")
+	ErrInvalidMaxUses   = errors.New("vouchers must be usable at least once")
+	ErrItemsUnsupported = errors.New("vouchers can't give furniture until players have inventories")
+)
+
+// CodeLength is the length of generated voucher codes.
+const CodeLength = 12
+
+// codeAlphabet leaves out characters that are easily confused with each other when typed in, like O & 0.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Voucher is a code players redeem in the purse for credits and/or items.
+type Voucher struct {
+	Code      string
+	Credits   int
+	Items     []string   // sprites of the furniture the voucher gives
+	MaxUses   int        // how many players can redeem the voucher, 1 for single-use vouchers
+	Uses      int        // how many players have redeemed the voucher
+	ExpiresAt *time.Time // nil for vouchers that never expire
+}
+
+// Check returns why the voucher can't be redeemed at now, or nil if it can.
+func (v *Voucher) Check(now time.Time) error {
+	switch {
+	case v.ExpiresAt != nil && !now.Before(*v.ExpiresAt):
+		return ErrExpired
+	case v.Uses >= v.MaxUses:
+		return ErrUsedUp
+	default:
+		return nil
+	}
+}
+
+// Redemption is a player redeeming a voucher.
+type Redemption struct {
+	Code       string
+	PlayerId   int
+	RedeemedAt time.Time
+}
+
+// Normalise formats a voucher code typed in by a player like the codes that are stored.
+func Normalise(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// generateCode returns a new random voucher code.
+func generateCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < CodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(codeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,146 @@
+package voucher
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jtieri/habbgo/game/credits"
+)
+
+type VoucherRepo struct {
+	database *sql.DB
+}
+
+// NewVoucherRepo returns a new instance of VoucherRepo.
+func NewVoucherRepo(db *sql.DB) *VoucherRepo {
+	return &VoucherRepo{database: db}
+}
+
+// Create stores new vouchers, none are stored if any of their codes is already taken.
+func (vr *VoucherRepo) Create(vouchers []Voucher) error {
+	tx, err := vr.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, v := range vouchers {
+		res, err := tx.Exec("INSERT INTO vouchers(code, credits, max_uses, expires_at) VALUES($1, $2, $3, $4) "+
+			"ON CONFLICT (code) DO NOTHING", v.Code, v.Credits, v.MaxUses, v.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrDuplicateVoucher
+		}
+
+		for _, item := range v.Items {
+			if _, err := tx.Exec("INSERT INTO voucher_items(code, sprite) VALUES($1, $2)", v.Code, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Redeem uses up the voucher for the player, giving them its credits, and returns the voucher along with the player's
+// credits afterwards. The voucher's items are returned for the caller to hand out.
+func (vr *VoucherRepo) Redeem(code string, playerId int, now time.Time) (*Voucher, int, error) {
+	tx, err := vr.database.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	v := &Voucher{}
+	err = tx.QueryRow("SELECT V.code, V.credits, V.max_uses, V.uses, V.expires_at FROM vouchers V "+
+		"WHERE V.code = $1 FOR UPDATE", code).Scan(&v.Code, &v.Credits, &v.MaxUses, &v.Uses, &v.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, 0, ErrInvalid
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	if err := v.Check(now); err != nil {
+		return nil, 0, err
+	}
+
+	v.Items, err = items(tx, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(v.Items) > 0 {
+		return nil, 0, ErrItemsUnsupported
+	}
+
+	res, err := tx.Exec("INSERT INTO voucher_redemptions(code, player_id, redeemed_at) VALUES($1, $2, $3) "+
+		"ON CONFLICT (code, player_id) DO NOTHING", code, playerId, now)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, 0, err
+	} else if n == 0 {
+		return nil, 0, ErrAlreadyRedeemed
+	}
+
+	if _, err := tx.Exec("UPDATE vouchers SET uses = uses + 1 WHERE code = $1", code); err != nil {
+		return nil, 0, err
+	}
+	v.Uses++
+
+	var balance int
+	if v.Credits > 0 {
+		balance, err = credits.Change(tx, playerId, v.Credits, credits.Voucher, code)
+	} else {
+		err = tx.QueryRow("SELECT P.credits FROM players P WHERE P.id = $1", playerId).Scan(&balance)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, balance, tx.Commit()
+}
+
+func items(tx *sql.Tx, code string) ([]string, error) {
+	rows, err := tx.Query("SELECT I.sprite FROM voucher_items I WHERE I.code = $1 ORDER BY I.id", code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sprites []string
+	for rows.Next() {
+		var sprite string
+		if err := rows.Scan(&sprite); err != nil {
+			return nil, err
+		}
+		sprites = append(sprites, sprite)
+	}
+
+	return sprites, rows.Err()
+}
+
+// Redemptions returns who redeemed the voucher and when, oldest first.
+func (vr *VoucherRepo) Redemptions(code string) ([]Redemption, error) {
+	rows, err := vr.database.Query("SELECT R.code, R.player_id, R.redeemed_at FROM voucher_redemptions R "+
+		"WHERE R.code = $1 ORDER BY R.redeemed_at, R.id", code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []Redemption
+	for rows.Next() {
+		var r Redemption
+		if err := rows.Scan(&r.Code, &r.PlayerId, &r.RedeemedAt); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, r)
+	}
+
+	return redemptions, rows.Err()
+}
@@ -0,0 +1,75 @@
+package voucher
+
+import (
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// VoucherService generates vouchers and redeems them for players.
+type VoucherService struct {
+	repo *VoucherRepo
+
+	log *zap.Logger
+}
+
+func NewVoucherService(log *zap.Logger, db *sql.DB) *VoucherService {
+	return &VoucherService{
+		repo: NewVoucherRepo(db),
+		log:  log,
+	}
+}
+
+// Generate creates a batch of count vouchers with random codes that each give credits & items to up to maxUses
+// players, until expiresAt unless it's nil. The new codes are returned. Vouchers giving items are refused with
+// ErrItemsUnsupported until players have inventories to put them in.
+func (vs *VoucherService) Generate(count, credits int, items []string, maxUses int, expiresAt *time.Time) ([]string, error) {
+	switch {
+	case count < 1:
+		return nil, ErrInvalidCount
+	case maxUses < 1:
+		return nil, ErrInvalidMaxUses
+	case len(items) > 0:
+		return nil, ErrItemsUnsupported
+	case credits <= 0:
+		return nil, ErrNothingToRedeem
+	}
+
+	vouchers := make([]Voucher, count)
+	codes := make([]string, count)
+	for i := range vouchers {
+		code, err := generateCode()
+		if err != nil {
+			return nil, err
+		}
+		vouchers[i] = Voucher{Code: code, Credits: credits, Items: items, MaxUses: maxUses, ExpiresAt: expiresAt}
+		codes[i] = code
+	}
+
+	if err := vs.repo.Create(vouchers); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Redeem uses up the voucher with the code the player typed in, giving them its credits. The voucher is returned
+// along with the player's credits afterwards. Vouchers giving items are left unused, see ErrItemsUnsupported.
+func (vs *VoucherService) Redeem(playerId int, code string) (*Voucher, int, error) {
+	v, balance, err := vs.repo.Redeem(Normalise(code), playerId, time.Now().UTC())
+	switch err {
+	case nil, ErrInvalid, ErrExpired, ErrUsedUp, ErrAlreadyRedeemed:
+	default:
+		vs.log.Warn("Failed to redeem voucher",
+			zap.Int("player_id", playerId),
+			zap.String("code", code),
+			zap.Error(err),
+		)
+	}
+	return v, balance, err
+}
+
+// Redemptions returns who redeemed the voucher and when, oldest first.
+func (vs *VoucherService) Redemptions(code string) ([]Redemption, error) {
+	return vs.repo.Redemptions(Normalise(code))
+}
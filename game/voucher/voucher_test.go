@@ -0,0 +1,55 @@
+package voucher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := generateCode()
+		require.NoError(t, err)
+		require.Len(t, code, CodeLength)
+		require.Equal(t, code, Normalise(code))
+		for _, c := range code {
+			require.True(t, strings.ContainsRune(codeAlphabet, c), "unexpected character %q", c)
+		}
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiry := now.Add(time.Hour)
+	v := &Voucher{Code: "ABC", Credits: 10, MaxUses: 2, Uses: 1, ExpiresAt: &expiry}
+
+	require.NoError(t, v.Check(now))
+	require.Equal(t, ErrExpired, v.Check(expiry))
+
+	v.Uses = 2
+	require.Equal(t, ErrUsedUp, v.Check(now))
+
+	v.ExpiresAt = nil
+	require.Equal(t, ErrUsedUp, v.Check(now.Add(24*time.Hour)))
+	require.Equal(t, "ABC-123", Normalise("  abc-123 "))
+}
+
+func TestGenerateChecks(t *testing.T) {
+	vs := &VoucherService{}
+
+	_, err := vs.Generate(0, 10, nil, 1, nil)
+	require.Equal(t, ErrInvalidCount, err)
+	_, err = vs.Generate(-1, 10, nil, 1, nil)
+	require.Equal(t, ErrInvalidCount, err)
+	_, err = vs.Generate(1, 10, nil, 0, nil)
+	require.Equal(t, ErrInvalidMaxUses, err)
+	_, err = vs.Generate(1, 10, []string{"throne"}, 1, nil)
+	require.Equal(t, ErrItemsUnsupported, err)
+	_, err = vs.Generate(1, 0, nil, 1, nil)
+	require.Equal(t, ErrNothingToRedeem, err)
+}
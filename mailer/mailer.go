@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jtieri/habbgo/crypto"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails such as password reset links & parent confirmation requests.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config is the mail configuration.
+type Config struct {
+	Transport string // outbox or smtp
+	From      string // address emails are sent from
+	Outbox    string // directory emails are written to by the outbox transport
+	SMTPHost  string
+	SMTPPort  int
+	Username  string // SMTP username, no authentication is used if empty
+	Password  string
+}
+
+// New returns the Mailer for the configured transport.
+func New(config *Config) (Mailer, error) {
+	switch strings.ToLower(config.Transport) {
+	case "", "outbox":
+		return &Outbox{Dir: config.Outbox, From: config.From}, nil
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, fmt.Errorf("smtp transport needs a host")
+		}
+		return &SMTP{
+			Addr:     fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort),
+			Host:     config.SMTPHost,
+			Username: config.Username,
+			Password: config.Password,
+			From:     config.From,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mail transport %q", config.Transport)
+	}
+}
+
+// Outbox is a Mailer for local development that writes every email to a .eml file instead of sending it.
+type Outbox struct {
+	Dir  string
+	From string
+}
+
+func (o *Outbox) Send(msg Message) error {
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), crypto.GenerateToken(4))
+	return os.WriteFile(filepath.Join(o.Dir, name), format(o.From, msg), 0o644)
+}
+
+// SMTP is a Mailer sending emails through an SMTP server, authenticating with PLAIN auth if a username is set.
+type SMTP struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTP) Send(msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	return smtp.SendMail(s.Addr, auth, s.From, []string{headerValue(msg.To)}, format(s.From, msg))
+}
+
+// format lays out msg as an RFC 5322 message.
+func format(from string, msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// headerValue strips line breaks so that a value can't inject extra headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
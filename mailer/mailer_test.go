@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(&Config{Transport: "outbox", Outbox: dir, From: "habbgo@localhost"})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(Message{To: "treebeard@example.com\r\nBcc: x@example.com", Subject: "Hi", Body: "a\nb"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "To: treebeard@example.comBcc: x@example.com\r\n")
+	require.Contains(t, string(raw), "\r\n\r\na\r\nb")
+}
+
+func TestNewRejectsUnknownTransport(t *testing.T) {
+	_, err := New(&Config{Transport: "pigeon"})
+	require.Error(t, err)
+
+	_, err = New(&Config{Transport: "smtp"})
+	require.Error(t, err)
+}
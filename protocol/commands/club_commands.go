@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jtieri/habbgo/game/credits"
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/protocol/messages"
+	"github.com/jtieri/habbgo/protocol/packets"
+)
+
+// SCR_GET_USER_INFO sends the player the state of their Habbo Club membership.
+func SCR_GET_USER_INFO(p *player.Player, packet *packets.IncomingPacket) {
+	p.Session.Send(messages.SCR_SINFO, messages.SCR_SINFO(p.Club, false))
+}
+
+// SCR_BUY buys or extends the player's Habbo Club membership with one of the configured options.
+func SCR_BUY(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 || restricted(p) {
+		return
+	}
+
+	_ = packet.ReadString() // product name, club_habbo is the only one
+	choice := packet.ReadInt()
+
+	wasMember := p.ClubMember()
+	s, balance, err := p.Services.PlayerService().Club().Buy(p.Details.Id, choice)
+	switch {
+	case err == credits.ErrNoCredits:
+		p.Session.Send(messages.NO_CREDITS, messages.NO_CREDITS())
+		return
+	case err != nil:
+		p.Session.Send(messages.ALERT, messages.ALERT("Your Habbo Club membership couldn't be bought, please try again later."))
+		return
+	}
+
+	p.Club = s
+	p.Details.Credits = balance
+	p.Session.Send(messages.CREDITBALANCE, messages.CREDITBALANCE(balance))
+	p.Session.Send(messages.SCR_SINFO, messages.SCR_SINFO(s, true))
+
+	// New members gain the club-only rights & clothing straight away
+	if !wasMember {
+		p.Session.Send(messages.RIGHTS, messages.RIGHTS(p.Rights()))
+		p.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS(
+			p.Services.PlayerService().Figures().AvailableSets(true)))
+	}
+}
+
+// SCR_GIFT_APPROVAL is sent when the player accepts their club gift, any gifts that have become due are given.
+func SCR_GIFT_APPROVAL(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 {
+		return
+	}
+	clubGifts(p)
+}
+
+// clubGifts gives the player the club gifts that have become due and tells them what they got.
+func clubGifts(p *player.Player) {
+	gifts := p.DeliverClubGifts()
+	if len(gifts) == 0 {
+		return
+	}
+
+	p.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf(
+		"Thank you for being a Habbo Club member! Your club gifts have arrived: %s.", strings.Join(gifts, ", "))))
+}
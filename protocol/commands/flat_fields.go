@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jtieri/habbgo/game/room"
+	"github.com/jtieri/habbgo/text"
+)
+
+const (
+	MAXROOMNAMELENGTH        = 25
+	MAXROOMDESCRIPTIONLENGTH = 128
+	MINROOMVISITORS          = 10
+	MAXROOMVISITORS          = 50
+)
+
+var errMalformedFlat = errors.New("malformed room packet")
+
+// newFlat holds the room a player asked to create with CREATEFLAT, sent as /floor/name/model/access/showOwner.
+type newFlat struct {
+	name      string
+	model     string
+	access    room.Access
+	showOwner bool
+}
+
+func parseNewFlat(body string) (*newFlat, error) {
+	data := strings.Split(body, "/")
+	if len(data) < 6 {
+		return nil, errMalformedFlat
+	}
+
+	name, err := flatName(data[2])
+	if err != nil {
+		return nil, err
+	}
+
+	model := data[3]
+	if !strings.HasPrefix(model, "model_") {
+		model = "model_" + model
+	}
+
+	return &newFlat{
+		name:      name,
+		model:     model,
+		access:    room.AccessType(data[4]),
+		showOwner: data[5] == "1",
+	}, nil
+}
+
+// flatUpdate holds the settings sent with UPDATEFLAT as roomId/name/access/showOwner.
+type flatUpdate struct {
+	roomId    int
+	name      string
+	access    room.Access
+	showOwner bool
+}
+
+func parseFlatUpdate(body string) (*flatUpdate, error) {
+	data := strings.Split(body, "/")
+	if len(data) < 4 {
+		return nil, errMalformedFlat
+	}
+
+	roomId, err := strconv.Atoi(data[0])
+	if err != nil {
+		return nil, errMalformedFlat
+	}
+	name, err := flatName(data[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &flatUpdate{
+		roomId:    roomId,
+		name:      name,
+		access:    room.AccessType(data[2]),
+		showOwner: data[3] == "1",
+	}, nil
+}
+
+// flatSettings holds the settings sent with SETFLATINFO as /roomId/ followed by key=value lines, nil fields weren't
+// sent.
+type flatSettings struct {
+	roomId      int
+	description *string
+	password    *string
+	superUsers  *bool
+	maxVisitors *int
+}
+
+func parseFlatSettings(body string) (*flatSettings, error) {
+	lines := strings.Split(body, "\r")
+
+	roomId, err := strconv.Atoi(strings.Trim(lines[0], "/"))
+	if err != nil {
+		return nil, errMalformedFlat
+	}
+
+	settings := &flatSettings{roomId: roomId}
+	for _, line := range lines[1:] {
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := kv[0], kv[1]
+
+		switch key {
+		case "description":
+			desc := text.Truncate(text.Filter(value), MAXROOMDESCRIPTIONLENGTH)
+			settings.description = &desc
+		case "password":
+			password := value
+			settings.password = &password
+		case "allsuperuser":
+			superUsers := value == "1"
+			settings.superUsers = &superUsers
+		case "maxvisitors":
+			max, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errMalformedFlat
+			}
+			if max < MINROOMVISITORS {
+				max = MINROOMVISITORS
+			} else if max > MAXROOMVISITORS {
+				max = MAXROOMVISITORS
+			}
+			settings.maxVisitors = &max
+		}
+	}
+
+	return settings, nil
+}
+
+// flatName validates a room name, returning it without the characters players can't use.
+func flatName(name string) (string, error) {
+	name = strings.TrimSpace(text.Filter(name))
+	if name == "" || utf8.RuneCountInString(name) > MAXROOMNAMELENGTH {
+		return "", errMalformedFlat
+	}
+	return name, nil
+}
+
+// flatId reads the room id sent as the whole body of GETFLATINFO & DELETEFLAT.
+func flatId(body string) (int, error) {
+	return strconv.Atoi(strings.Trim(strings.TrimSpace(body), "/"))
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jtieri/habbgo/game/room"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNewFlat(t *testing.T) {
+	flat, err := parseNewFlat("/first floor/My Room/a/password/1")
+	require.NoError(t, err)
+	require.Equal(t, &newFlat{name: "My Room", model: "model_a", access: room.Password, showOwner: true}, flat)
+
+	flat, err = parseNewFlat("/first floor/Den/model_h/open/0")
+	require.NoError(t, err)
+	require.Equal(t, "model_h", flat.model)
+	require.False(t, flat.showOwner)
+
+	_, err = parseNewFlat("/first floor/   /a/open/1")
+	require.Equal(t, errMalformedFlat, err)
+	_, err = parseNewFlat("/first floor/My Room")
+	require.Equal(t, errMalformedFlat, err)
+}
+
+func TestParseFlatUpdate(t *testing.T) {
+	update, err := parseFlatUpdate("12/Renamed/closed/0")
+	require.NoError(t, err)
+	require.Equal(t, &flatUpdate{roomId: 12, name: "Renamed", access: room.Closed}, update)
+
+	_, err = parseFlatUpdate("x/Renamed/closed/0")
+	require.Equal(t, errMalformedFlat, err)
+}
+
+func TestParseFlatSettings(t *testing.T) {
+	settings, err := parseFlatSettings("/12/\rdescription=Come chat\rpassword=secret\rallsuperuser=1\rmaxvisitors=99")
+	require.NoError(t, err)
+	require.Equal(t, 12, settings.roomId)
+	require.Equal(t, "Come chat", *settings.description)
+	require.Equal(t, "secret", *settings.password)
+	require.True(t, *settings.superUsers)
+	require.Equal(t, MAXROOMVISITORS, *settings.maxVisitors)
+
+	settings, err = parseFlatSettings("/12/\rmaxvisitors=1")
+	require.NoError(t, err)
+	require.Nil(t, settings.description)
+	require.Equal(t, MINROOMVISITORS, *settings.maxVisitors)
+
+	settings, err = parseFlatSettings("/12/\rdescription=" + strings.Repeat("é", MAXROOMDESCRIPTIONLENGTH+1))
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("é", MAXROOMDESCRIPTIONLENGTH), *settings.description)
+
+	_, err = parseFlatSettings("/twelve/")
+	require.Equal(t, errMalformedFlat, err)
+}
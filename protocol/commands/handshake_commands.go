@@ -11,21 +11,38 @@ func INIT_CRYPTO(player *player.Player, packet *packets.IncomingPacket) {
 }
 
 func GENERATEKEY(player *player.Player, packet *packets.IncomingPacket) {
-	player.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS())
+	player.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS(player.Services.PlayerService().Figures().AvailableSets(player.ClubMember())))
 	player.Session.Send(messages.ENDCRYPTO, messages.ENDCRYPTO())
 	//player.Session.Send(composers.SECRETKEY())
 }
 
 func GET_SESSION_PARAMETERS(player *player.Player, packet *packets.IncomingPacket) {
-	player.Session.Send(messages.SESSIONPARAMETERS, messages.SESSIONPARAMETERS())
+	player.Session.Send(messages.SESSIONPARAMETERS, messages.SESSIONPARAMETERS(player.Services.PlayerService().Config()))
 }
 
 func VERSIONCHECK(player *player.Player, packet *packets.IncomingPacket) {
 
 }
 
-func UNIQUEID(player *player.Player, packet *packets.IncomingPacket) {
+func UNIQUEID(p *player.Player, packet *packets.IncomingPacket) {
+	machineId := packet.ReadString()
 
+	if !player.ValidMachineId(machineId) {
+		if !p.Services.PlayerService().Config().IssueMachineIds {
+			return
+		}
+
+		// The client stores the new ID and sends it back in UNIQUEID from now on.
+		machineId = player.NewMachineId()
+		p.Session.Send(messages.MACHINEID, messages.MACHINEID(machineId))
+	}
+
+	p.MachineId = machineId
+
+	if b := p.Banned(); b != nil {
+		p.Session.Send(messages.USER_BANNED, messages.USER_BANNED(b.Message()))
+		p.Session.Close()
+	}
 }
 
 func SECRETKEY(player *player.Player, packets *packets.IncomingPacket) {
@@ -33,13 +50,13 @@ func SECRETKEY(player *player.Player, packets *packets.IncomingPacket) {
 }
 
 func SSO(p *player.Player, packet *packets.IncomingPacket) {
-	token := packet.ReadString()
+	ticket := packet.ReadString()
 
-	// TODO if p login with token is success login, otherwise send LOCALISED ERROR & disconnect from server
-	if token == "" {
-		p.Login()
+	if player.LoginSSO(p, ticket) {
+		login(p)
 	} else {
-
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid SSO ticket."))
+		p.Session.Close()
 	}
 }
 
@@ -47,10 +64,55 @@ func TRY_LOGIN(p *player.Player, packet *packets.IncomingPacket) {
 	username := packet.ReadString()
 	password := packet.ReadString()
 
+	if !p.Services.PlayerService().AllowLogin(p.Session.Address()) {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Too many login attempts, please try again in a minute."))
+		return
+	}
+
 	if player.LoginDB(p, username, password) {
-		p.Login()
-		p.Session.Send(messages.LOGINOK, messages.LOGINOK())
+		login(p)
 	} else {
 		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid Login Credentials."))
 	}
 }
+
+// login finishes logging in a player whose credentials have been verified,
+// unless the player is banned in which case they are sent USER_BANNED and disconnected.
+func login(p *player.Player) {
+	if b := p.Banned(); b != nil {
+		p.Session.Send(messages.USER_BANNED, messages.USER_BANNED(b.Message()))
+		p.Session.Close()
+		return
+	}
+
+	p.Login()
+	p.Session.Send(messages.LOGINOK, messages.LOGINOK())
+	p.Session.Send(messages.RIGHTS, messages.RIGHTS(p.Rights()))
+
+	if p.Restricted() {
+		p.Session.Send(messages.ALERT, messages.ALERT(
+			"Your account is waiting for your parent or guardian to confirm it, until then some features are disabled."))
+	}
+
+	// AVAILABLESETS was sent before anyone logged in, members are sent the club sets once we know who they are.
+	if p.ClubMember() {
+		p.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS(p.Services.PlayerService().Figures().AvailableSets(true)))
+	}
+
+	clubGifts(p)
+}
+
+// GET_PASSWORD emails a password reset link to the account with the given username & email address. The reply is
+// the same whether or not the details match an account, or whether the request was throttled, see
+// PlayerService.AllowPasswordReset & ResetRepo.CreateReset.
+func GET_PASSWORD(p *player.Player, packet *packets.IncomingPacket) {
+	username := packet.ReadString()
+	email := packet.ReadString()
+
+	if username != "" && email != "" && p.Services.PlayerService().AllowPasswordReset(p.Session.Address()) {
+		go p.Services.PlayerService().RequestPasswordReset(username, email)
+	}
+
+	p.Session.Send(messages.ALERT, messages.ALERT(
+		"If the details you entered match an account, a link to reset its password has been emailed to you."))
+}
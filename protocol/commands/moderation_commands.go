@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jtieri/habbgo/game/ban"
+	"github.com/jtieri/habbgo/game/fuse"
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/protocol/messages"
+	"github.com/jtieri/habbgo/protocol/packets"
+)
+
+const ( // MODERATORACTION command categories & actions
+	modCategoryUser = 0
+	modActionAlert  = 0
+	modActionKick   = 1
+	modActionBan    = 2
+)
+
+// modActionRights are the fuse rights needed for each MODERATORACTION action, actions without one aren't allowed.
+var modActionRights = map[int]fuse.Right{
+	modActionAlert: fuse.Alert,
+	modActionKick:  fuse.Kick,
+	modActionBan:   fuse.Ban,
+}
+
+func MODERATORACTION(p *player.Player, packet *packets.IncomingPacket) {
+	category := packet.ReadInt()
+	action := packet.ReadInt()
+
+	right, ok := modActionRights[action]
+	if category != modCategoryUser || !ok || !p.HasRight(right) {
+		return
+	}
+
+	switch action {
+	case modActionAlert, modActionKick:
+		message := packet.ReadString()
+		packet.ReadString() // extra info
+		name := packet.ReadString()
+
+		if action == modActionAlert {
+			alertPlayer(p, name, message)
+		} else {
+			kickPlayer(p, name, message)
+		}
+	case modActionBan:
+		message := packet.ReadString()
+		packet.ReadString() // extra info, only used by the hobba tool for staff notes
+		name := packet.ReadString()
+		hours := packet.ReadInt()
+		banMachine := packet.ReadBool()
+		banIp := packet.ReadBool()
+
+		banPlayer(p, name, message, time.Duration(hours)*time.Hour, banMachine, banIp)
+	}
+}
+
+// alertPlayer sends the online player with the given name an alert from staff.
+func alertPlayer(staff *player.Player, name, message string) {
+	target := staff.Services.PlayerService().OnlinePlayerByName(name)
+	if target == nil {
+		staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s is not online.", name)))
+		return
+	}
+
+	target.Session.Send(messages.ALERT, messages.ALERT(message))
+	staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s has been alerted.", name)))
+}
+
+// kickPlayer sends the online player with the given name from their room back to the hotel view, with an alert
+// telling them why.
+func kickPlayer(staff *player.Player, name, message string) {
+	target := staff.Services.PlayerService().OnlinePlayerByName(name)
+	if target == nil {
+		staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s is not in a room.", name)))
+		return
+	}
+	if roomId, _ := staff.Services.PlayerService().Room(target); roomId == 0 {
+		staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s is not in a room.", name)))
+		return
+	}
+	if target.Details.PlayerRank >= staff.Details.PlayerRank {
+		staff.Session.Send(messages.ALERT, messages.ALERT("You can't kick staff of an equal or higher rank."))
+		return
+	}
+
+	staff.Services.PlayerService().LeaveRoom(target)
+	target.Session.Send(messages.HOTEL_VIEW, messages.HOTEL_VIEW())
+	if message != "" {
+		target.Session.Send(messages.ALERT, messages.ALERT(message))
+	}
+	staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s has been kicked.", name)))
+}
+
+// banPlayer bans the player with the given name, along with their IP address and/or machine ID, and disconnects
+// them. Offline players' IP address & machine ID are taken from their last login. A duration of zero bans permanently.
+func banPlayer(staff *player.Player, name, reason string, duration time.Duration, banMachine, banIp bool) {
+	bans := staff.Services.PlayerService().Bans()
+
+	targetId, found := player.LookupPlayerId(staff, name)
+	if !found {
+		staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("Player %s does not exist.", name)))
+		return
+	}
+
+	rank, err := player.LookupPlayerRank(staff, targetId)
+	if err != nil {
+		staff.Session.Send(messages.ALERT, messages.ALERT("Failed to ban "+name+"."))
+		return
+	}
+	if rank >= staff.Details.PlayerRank {
+		staff.Session.Send(messages.ALERT, messages.ALERT("You can't ban staff of an equal or higher rank."))
+		return
+	}
+
+	ps := staff.Services.PlayerService()
+	target := ps.OnlinePlayerById(targetId)
+
+	b, err := bans.BanAccount(targetId, reason, staff.Details.Id, duration)
+	if err != nil {
+		staff.Session.Send(messages.ALERT, messages.ALERT("Failed to ban "+name+"."))
+		return
+	}
+
+	// Offline players are banned from the machine & IP address of their last login
+	var address, machineId string
+	if target != nil {
+		address, machineId = target.Session.Address(), target.MachineId
+	} else if logins, err := ps.Logins().History(targetId, 1); err == nil && len(logins) > 0 {
+		address, machineId = logins[0].Address, logins[0].MachineId
+	}
+
+	var skipped []string
+	if banIp {
+		if address == "" {
+			skipped = append(skipped, "IP address")
+		} else if _, err := bans.Ban(ban.IP, address, reason, staff.Details.Id, duration); err != nil {
+			skipped = append(skipped, "IP address")
+		}
+	}
+	if banMachine {
+		if machineId == "" {
+			skipped = append(skipped, "machine")
+		} else if _, err := bans.Ban(ban.Machine, machineId, reason, staff.Details.Id, duration); err != nil {
+			skipped = append(skipped, "machine")
+		}
+	}
+
+	if target != nil {
+		target.Session.Send(messages.USER_BANNED, messages.USER_BANNED(b.Message()))
+		target.Session.Close()
+	}
+
+	if len(skipped) > 0 {
+		staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s has been banned, but their %s couldn't be "+
+			"banned.", name, strings.Join(skipped, " & "))))
+		return
+	}
+	staff.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("%s has been banned.", name)))
+}
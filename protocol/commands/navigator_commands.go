@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/jtieri/habbgo/game/navigator"
 	"github.com/jtieri/habbgo/game/player"
 	"github.com/jtieri/habbgo/game/room"
@@ -15,7 +18,7 @@ func Navigate(player *player.Player, packet *packets.IncomingPacket) {
 	catId := packet.ReadInt()
 
 	if catId >= room.PublicRoomOffset {
-		r := roomService.RoomById(catId - room.PublicRoomOffset)
+		r := roomService.LoadRoom(catId - room.PublicRoomOffset)
 		if r != nil {
 			catId = r.Details.CategoryID
 		}
@@ -23,51 +26,191 @@ func Navigate(player *player.Player, packet *packets.IncomingPacket) {
 
 	category := player.Services.NavigatorService().CategoryById(catId)
 
-	// TODO also check that access rank isnt higher than players rank
-	if category == nil {
+	if category == nil || !player.HasRight(category.AccessRight) {
+		return
+	}
+
+	if category.ClubOnly && !player.ClubMember() {
+		player.Session.Send(messages.ALERT, messages.ALERT("Only Habbo Club members can visit these rooms."))
 		return
 	}
 
 	subCategories := player.Services.NavigatorService().CategoriesByParentId(category.ID)
 	// sort categories by player count
 
-	r := player.Services.RoomService().Rooms()
-	currentVisitors := navigator.CurrentVisitors(category, r)
-	maxVisitors := navigator.MaxVisitors(category, r)
+	currentVisitors, maxVisitors := roomService.CategoryVisitors(category.ID)
 
-	var rooms []*room.Room
+	var candidates []*room.Room
 	if category.IsPublic {
-		for _, r := range roomService.ReplaceRooms(roomService.RoomsByPlayerId(0)) {
-			if r.Details.CategoryID == category.ID && (!hideFullRooms) && r.Details.CurrentVisitors < r.Details.MaxVisitors {
-				// if room is hidden or category id is not equal to the category id we are working with currently continue
-				if r.Details.Hidden || r.Details.CategoryID != category.ID {
-					continue
-				}
-
-				// if we are hiding full rooms in the navigator and the room is full continue
-				if hideFullRooms && r.Details.CurrentVisitors >= r.Details.MaxVisitors {
-					continue
-				}
-
-				rooms = append(rooms, r)
-			}
-		}
+		candidates = roomService.ReplaceRooms(roomService.RoomsByPlayerId(0))
 	} else {
-		// TODO finish private room logic
+		candidates = roomService.PrivateRoomsByCategoryId(category.ID, navigator.PrivateRoomLimit)
 	}
 
-	//// ----------
-	//fmt.Println("--------------------")
-	//fmt.Println(category.Name)
-	//for _, c := range subCategories {
-	//	fmt.Println(c.Name)
-	//}
-	//fmt.Println("--------------------")
-	//for _, r := range rooms {
-	//	fmt.Println(r.Details.Name)
-	//}
+	var rooms []*room.Room
+	for _, r := range candidates {
+		// if room is hidden or category id is not equal to the category id we are working with currently continue
+		if r.Details.Hidden || r.Details.CategoryID != category.ID {
+			continue
+		}
 
-	// TODO sort rooms by player count before sending NAVNODEINFO
+		// if we are hiding full rooms in the navigator and the room is full continue
+		if hideFullRooms && r.Details.CurrentVisitors >= r.Details.MaxVisitors {
+			continue
+		}
+
+		rooms = append(rooms, r)
+	}
+
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return rooms[i].Details.CurrentVisitors > rooms[j].Details.CurrentVisitors
+	})
 
 	player.Session.Send(messages.NAVNODEINFO, messages.NAVNODEINFO(player, category, hideFullRooms, subCategories, rooms, currentVisitors, maxVisitors))
 }
+
+// SUSERF lists the rooms of the player with the given name, the navigator's own rooms tab sends the player's own name.
+func SUSERF(player *player.Player, packet *packets.IncomingPacket) {
+	username := packet.ReadString()
+
+	var rooms []*room.Room
+	for _, r := range player.Services.RoomService().RoomsByOwnerName(username) {
+		if r.Details.Hidden && r.Details.OwnerId != player.Details.Id {
+			continue
+		}
+		rooms = append(rooms, r)
+	}
+
+	if len(rooms) == 0 {
+		player.Session.Send(messages.NOFLATSFORUSER, messages.NOFLATSFORUSER(username))
+		return
+	}
+	player.Session.Send(messages.FLAT_RESULTS, messages.FLAT_RESULTS(player, rooms))
+}
+
+// SRCHF searches guest rooms by their name or owner's name.
+func SRCHF(player *player.Player, packet *packets.IncomingPacket) {
+	query := strings.TrimSpace(packet.ReadString())
+	if query == "" {
+		player.Session.Send(messages.NOFLATS, messages.NOFLATS())
+		return
+	}
+
+	rooms := player.Services.RoomService().SearchRooms(query, navigator.PrivateRoomLimit)
+	if len(rooms) == 0 {
+		player.Session.Send(messages.NOFLATS, messages.NOFLATS())
+		return
+	}
+	player.Session.Send(messages.SEARCH_FLAT_RESULTS, messages.SEARCH_FLAT_RESULTS(player, rooms))
+}
+
+// SBUSYF lists the guest rooms with the most visitors.
+func SBUSYF(player *player.Player, packet *packets.IncomingPacket) {
+	rooms := player.Services.RoomService().PopularRooms(navigator.PrivateRoomLimit)
+	if len(rooms) == 0 {
+		player.Session.Send(messages.NOFLATS, messages.NOFLATS())
+		return
+	}
+	player.Session.Send(messages.SEARCH_FLAT_RESULTS, messages.SEARCH_FLAT_RESULTS(player, rooms))
+}
+
+// GETFVRF lists the player's favourite rooms.
+func GETFVRF(player *player.Player, packet *packets.IncomingPacket) {
+	if player.Details.Id == 0 {
+		return
+	}
+
+	var rooms []*room.Room
+	for _, r := range player.Services.RoomService().Favourites(player.Details.Id) {
+		if canSeeRoom(player, r) {
+			rooms = append(rooms, r)
+		}
+	}
+	player.Session.Send(messages.FAVOURITE_ROOMS, messages.FAVOURITE_ROOMS(player, rooms))
+}
+
+// ADD_FAVORITE_ROOM adds a public or guest room the player can see to their favourites.
+func ADD_FAVORITE_ROOM(player *player.Player, packet *packets.IncomingPacket) {
+	r := favouriteRoom(player, packet)
+	if r == nil || !canSeeRoom(player, r) {
+		return
+	}
+
+	max := player.Services.PlayerService().Config().MaxFavouriteRooms
+	err := player.Services.RoomService().AddFavourite(player.Details.Id, r.Details.Id, max)
+	if err == room.ErrTooManyFavourites {
+		player.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("nav_error_toomanyfavrooms"))
+	}
+}
+
+// DEL_FAVORITE_ROOM removes a room from the player's favourites.
+func DEL_FAVORITE_ROOM(player *player.Player, packet *packets.IncomingPacket) {
+	r := favouriteRoom(player, packet)
+	if r == nil {
+		return
+	}
+	player.Services.RoomService().RemoveFavourite(player.Details.Id, r.Details.Id)
+}
+
+// favouriteRoom reads whether a room is public & its id, then returns the room or nil if there is no such room.
+// Public rooms are sent with room.PublicRoomOffset added to their id.
+func favouriteRoom(player *player.Player, packet *packets.IncomingPacket) *room.Room {
+	if player.Details.Id == 0 {
+		return nil
+	}
+
+	public := packet.ReadInt() == 1
+	roomId := packet.ReadInt()
+	if public && roomId >= room.PublicRoomOffset {
+		roomId -= room.PublicRoomOffset
+	}
+
+	r := player.Services.RoomService().LoadRoom(roomId)
+	if r == nil || player.Services.RoomService().PublicRoom(r) != public {
+		return nil
+	}
+	return r
+}
+
+// canSeeRoom reports whether the room is listed for the player, hidden rooms are only listed for their owner.
+func canSeeRoom(player *player.Player, r *room.Room) bool {
+	if r.Details.Hidden && r.Details.OwnerId != player.Details.Id {
+		return false
+	}
+	category := player.Services.NavigatorService().CategoryById(r.Details.CategoryID)
+	return category == nil || player.HasRight(category.AccessRight)
+}
+
+// GETPARENTCHAIN sends the ancestors of a navigator category.
+func GETPARENTCHAIN(player *player.Player, packet *packets.IncomingPacket) {
+	nav := player.Services.NavigatorService()
+
+	category := nav.CategoryById(packet.ReadInt())
+	if category == nil || !player.HasRight(category.AccessRight) {
+		return
+	}
+	player.Session.Send(messages.PARENTCHAIN, messages.PARENTCHAIN(category, nav.ParentChain(category.ID)))
+}
+
+// GETSPACENODEUSERS lists the players currently in a public room, the node id is the room's id plus
+// room.PublicRoomOffset.
+func GETSPACENODEUSERS(player *player.Player, packet *packets.IncomingPacket) {
+	nodeId := packet.ReadInt()
+	if nodeId < room.PublicRoomOffset {
+		return
+	}
+
+	rs := player.Services.RoomService()
+	r := rs.LoadRoom(nodeId - room.PublicRoomOffset)
+	if r == nil || !rs.PublicRoom(r) || !canSeeRoom(player, r) {
+		return
+	}
+
+	var usernames []string
+	for _, p := range player.Services.PlayerService().PlayersInRoom(r.Details.Id) {
+		usernames = append(usernames, p.Details.Username)
+	}
+	sort.Strings(usernames)
+
+	player.Session.Send(messages.SPACENODEUSERS, messages.SPACENODEUSERS(nodeId, usernames))
+}
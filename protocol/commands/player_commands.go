@@ -1,7 +1,14 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/jtieri/habbgo/game/badge"
+	"github.com/jtieri/habbgo/game/credits"
 	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/game/purse"
+	"github.com/jtieri/habbgo/game/voucher"
 	"github.com/jtieri/habbgo/protocol/messages"
 	"github.com/jtieri/habbgo/protocol/packets"
 )
@@ -11,15 +18,192 @@ func GET_INFO(player *player.Player, packet *packets.IncomingPacket) {
 }
 
 func GET_CREDITS(player *player.Player, packet *packets.IncomingPacket) {
+	if player.Details.Id != 0 {
+		player.RefreshCredits()
+	}
 	player.Session.Send(messages.CREDITBALANCE, messages.CREDITBALANCE(player.Details.Credits))
 }
 
+// GETUSERCREDITLOG sends the player their most recent credit transactions.
+func GETUSERCREDITLOG(player *player.Player, packet *packets.IncomingPacket) {
+	if player.Details.Id == 0 {
+		return
+	}
+
+	transactions, err := player.Services.PlayerService().Credits().Log(player.Details.Id)
+	if err != nil {
+		player.Session.Send(messages.ALERT, messages.ALERT("Your transactions couldn't be loaded, please try again later."))
+		return
+	}
+	player.Session.Send(messages.CREDITLOG, messages.CREDITLOG(transactions))
+}
+
+const ( // Used in VOUCHER_REDEEM_ERROR, the client localises them as purse_vouchers_error<code>
+	voucherTechnicalError = "0"
+	voucherInvalid        = "1" // the code doesn't exist, has expired or has been used up
+)
+
+// REDEEM_VOUCHER redeems the voucher code the player typed into the purse.
+func REDEEM_VOUCHER(player *player.Player, packet *packets.IncomingPacket) {
+	if player.Details.Id == 0 || !player.Services.PlayerService().Config().VoucherEnabled || restricted(player) {
+		return
+	}
+
+	v, balance, err := player.Services.PlayerService().Vouchers().Redeem(player.Details.Id, packet.ReadString())
+	switch err {
+	case nil:
+	case voucher.ErrInvalid, voucher.ErrExpired, voucher.ErrUsedUp, voucher.ErrAlreadyRedeemed:
+		player.Session.Send(messages.VOUCHER_REDEEM_ERROR, messages.VOUCHER_REDEEM_ERROR(voucherInvalid))
+		return
+	default:
+		player.Session.Send(messages.VOUCHER_REDEEM_ERROR, messages.VOUCHER_REDEEM_ERROR(voucherTechnicalError))
+		return
+	}
+
+	player.Details.Credits = balance
+	player.Session.Send(messages.VOUCHER_REDEEM_OK, messages.VOUCHER_REDEEM_OK(v.Items))
+	player.Session.Send(messages.CREDITBALANCE, messages.CREDITBALANCE(balance))
+}
+
+// BTCKS buys one of the ticket bundles, for the player or as a gift for the player with the given name.
+func BTCKS(p *player.Player, packet *packets.IncomingPacket) {
+	choice := packet.ReadInt()
+	name := packet.ReadString()
+	buyPurseItem(p, purse.Tickets, choice, name)
+}
+
+// GRPC buys a product from the catalogue. Until the catalogue is handled only the film bundles are sold, they're
+// found by their purse.Bundle SaleCode.
+func GRPC(p *player.Player, packet *packets.IncomingPacket) {
+	saleCode, giftTo, ok := parsePurchase(packet.Text())
+	if !ok {
+		return
+	}
+
+	choice, found := p.Services.PlayerService().Config().Purse.FilmChoice(saleCode)
+	if !found {
+		return
+	}
+	buyPurseItem(p, purse.Film, choice, giftTo)
+}
+
+// parsePurchase reads the sale code and, for gifts, the recipient's name from the body of GRPC. Its lines are
+// separated by \r, the sale code is the 4th and gifts have "1" & the recipient's name as the 6th & 7th.
+func parsePurchase(body string) (saleCode, giftTo string, ok bool) {
+	data := strings.Split(body, "\r")
+	if len(data) < 4 || data[3] == "" {
+		return "", "", false
+	}
+	if len(data) >= 7 && data[5] == "1" {
+		giftTo = data[6]
+	}
+	return data[3], giftTo, true
+}
+
+// buyPurseItem buys the ticket or film bundle at the 1 based position choice, for the player or as a gift for the
+// player with the given name.
+func buyPurseItem(p *player.Player, item purse.Item, choice int, name string) {
+	if p.Details.Id == 0 || restricted(p) {
+		return
+	}
+
+	recipientId := p.Details.Id
+	if name != "" && !strings.EqualFold(name, p.Details.Username) {
+		id, found := player.LookupPlayerId(p, name)
+		if !found {
+			p.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("There is no Habbo called %s.", name)))
+			return
+		}
+		recipientId = id
+	}
+
+	ps := p.Services.PlayerService()
+	recipient, err := ps.BuyPurseItem(p, recipientId, item, choice)
+	switch err {
+	case nil:
+	case credits.ErrNoCredits:
+		p.Session.Send(messages.NO_CREDITS, messages.NO_CREDITS())
+		return
+	case purse.ErrUnknownBundle:
+		return
+	default:
+		p.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("Your %s couldn't be bought, please try again later.", item)))
+		return
+	}
+
+	bundles := ps.Config().Purse.Tickets
+	if item == purse.Film {
+		bundles = ps.Config().Purse.Film
+	}
+	bundle := bundles[choice-1]
+
+	p.Session.Send(messages.CREDITBALANCE, messages.CREDITBALANCE(p.Details.Credits))
+	if item == purse.Tickets {
+		p.Session.Send(messages.TICKETS_BUY, messages.TICKETS_BUY(bundle.Amount))
+	}
+
+	if recipient != nil {
+		if item == purse.Tickets {
+			recipient.Session.Send(messages.TICKETS, messages.TICKETS(recipient.Details.Tickets))
+		} else {
+			recipient.Session.Send(messages.FILM, messages.FILM(recipient.Details.Film))
+		}
+		if recipient != p {
+			recipient.Session.Send(messages.ALERT, messages.ALERT(
+				fmt.Sprintf("%s has given you %d %s.", p.Details.Username, bundle.Amount, item)))
+		}
+	}
+}
+
 func GETAVAILABLEBADGES(player *player.Player, packet *packets.IncomingPacket) {
-	player.Session.Send(messages.AVAILABLESETS, messages.AVAILABLEBADGES(player))
+	player.Session.Send(messages.AVAILABLEBADGES, messages.AVAILABLEBADGES(player))
+}
+
+// SETBADGE stores the badge the player chose to wear and whether it's shown, then shows it to everyone in the
+// player's room.
+func SETBADGE(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 {
+		return
+	}
+
+	selection := badge.Selection{Code: packet.ReadString(), Visible: packet.ReadBool()}
+
+	err := p.Services.PlayerService().Badges().Select(p.Details.Id, p.Details.PlayerRank, selection)
+	if err != nil {
+		p.Session.Send(messages.AVAILABLEBADGES, messages.AVAILABLEBADGES(p))
+		return
+	}
+
+	p.Details.CurrentBadge = selection.Code
+	p.Details.DisplayBadge = selection.Visible
+
+	roomId, unitId := p.Services.PlayerService().Room(p)
+	for _, other := range p.Services.PlayerService().PlayersInRoom(roomId) {
+		other.Session.Send(messages.USERBADGE, messages.USERBADGE(unitId, selection.Code, selection.Visible))
+	}
 }
 
 func GET_SOUND_SETTING(player *player.Player, packet *packets.IncomingPacket) {
-	player.Session.Send(messages.SOUNDSETTING, messages.SOUNDSETTING(player.Details.SoundEnabled))
+	player.Session.Send(messages.SOUNDSETTING, messages.SOUNDSETTING(preferences(player).SoundEnabled))
+}
+
+// SET_SOUND_SETTING stores the player's choice of whether the client plays sounds.
+func SET_SOUND_SETTING(player *player.Player, packet *packets.IncomingPacket) {
+	if player.Preferences == nil {
+		return
+	}
+
+	player.Preferences.SoundEnabled = packet.ReadBool()
+	_ = player.SavePreferences() // the client has already toggled its sound, so there's nothing to reply
+}
+
+// preferences returns the player's Preferences, or the defaults if they haven't logged in yet.
+func preferences(p *player.Player) *player.Preferences {
+	if p.Preferences != nil {
+		return p.Preferences
+	}
+	defaults := p.Services.PlayerService().Config().DefaultPreferences
+	return &defaults
 }
 
 func TestLatency(player *player.Player, packet *packets.IncomingPacket) {
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePurchase(t *testing.T) {
+	saleCode, giftTo, ok := parsePurchase("production\r-\r12\rfilm\r\r0")
+	require.True(t, ok)
+	require.Equal(t, "film", saleCode)
+	require.Equal(t, "", giftTo)
+
+	saleCode, giftTo, ok = parsePurchase("production\r-\r12\rfilm\r\r1\rlazar\rHave fun!")
+	require.True(t, ok)
+	require.Equal(t, "film", saleCode)
+	require.Equal(t, "lazar", giftTo)
+
+	_, _, ok = parsePurchase("production\r-\r12")
+	require.False(t, ok)
+}
@@ -4,8 +4,8 @@ import (
 	"net/mail"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/jtieri/habbgo/crypto"
 	"github.com/jtieri/habbgo/date"
 	"github.com/jtieri/habbgo/game/player"
 	"github.com/jtieri/habbgo/protocol/messages"
@@ -14,7 +14,7 @@ import (
 )
 
 const (
-	ALLOWEDCHARS          = "1234567890qwertyuiopasdfghjklzxcvbnm_-+=?!@:.,$" // TODO make this a config option
+	ALLOWEDCHARS          = player.AllowedChars
 	OK                    = 0
 	NAMETOOLONG           = 1
 	NAMETOOSHORT          = 2
@@ -25,10 +25,15 @@ const (
 	PASSWORDUNACCEPTABLE  = 3
 	PASSWORDHASNONUM      = 4
 	PASSWORDSIMILARTONAME = 5
+	ACCOUNTUPDATED        = 0 // UPDATE_ACCOUNT error codes
+	INCORRECTPASSWORD     = 1
+	INCORRECTBIRTHDAY     = 2
+	MAXMOTTOLENGTH        = 50
+	BIRTHDAYFORMAT        = "02.01.2006" // birthdays are sent by the client as dd.MM.yyyy
 )
 
 func GETAVAILABLESETS(p *player.Player, packet *packets.IncomingPacket) {
-	p.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS())
+	p.Session.Send(messages.AVAILABLESETS, messages.AVAILABLESETS(p.Services.PlayerService().Figures().AvailableSets(p.ClubMember())))
 }
 
 func GDATE(p *player.Player, packet *packets.IncomingPacket) {
@@ -56,52 +61,287 @@ func APPROVEEMAIL(p *player.Player, packet *packets.IncomingPacket) {
 	}
 }
 
+// REGISTER creates a new account from the registration module's fields, replying with REGOK once the player
+// has been stored or with the reason the registration was rejected.
 func REGISTER(p *player.Player, packet *packets.IncomingPacket) {
-	packet.ReadB64()
-	username := packet.ReadString()
+	fields, err := readRegFields(packet)
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid registration."))
+		return
+	}
 
-	packet.ReadB64()
-	figure := packet.ReadString()
+	reg, err := newRegistration(fields, time.Now())
+	switch {
+	case err == errInvalidEmail:
+		p.Session.Send(messages.EMAIL_REJECTED, messages.EMAIL_REJECTED())
+		return
+	case err == errInvalidBirthday:
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid birthday."))
+		return
+	case err != nil:
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid registration."))
+		return
+	}
 
-	packet.ReadB64()
-	gender := packet.ReadString()
+	ps := p.Services.PlayerService()
+	underAge := ps.UnderAge(reg.birthday)
+	switch {
+	case underAge && ps.Config().Coppa > 1:
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("You are too young to register."))
+		return
+	case underAge && !reg.parentAgree:
+		p.Session.Send(messages.LOCALISED_ERROR,
+			messages.LOCALISED_ERROR("Your parent or guardian must agree to you registering."))
+		return
+	}
 
-	packet.ReadB64()
-	packet.ReadB64()
+	if code := checkName(p, reg.name); code != OK {
+		p.Session.Send(messages.APPROVENAMEREPLY, messages.APPROVENAMEREPLY(code))
+		return
+	}
 
-	packet.ReadB64()
-	email := packet.ReadString()
+	if code := checkPassword(p, reg.name, reg.password); code != OK {
+		p.Session.Send(messages.PASSWORD_APPROVED, messages.PASSWORD_APPROVED(code))
+		return
+	}
 
-	packet.ReadB64()
-	birthday := packet.ReadString()
+	if errMsg := checkProfile(p, reg.figure, reg.sex, reg.motto); errMsg != "" {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR(errMsg))
+		return
+	}
 
-	packet.ReadBytes(11)
-	password := packet.ReadString()
+	hash, err := ps.PasswordHasher().Hash(reg.password)
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Registration failed, please try again."))
+		return
+	}
+
+	// Under age accounts stay restricted until the parent email sent with SEND_PARENT_EMAIL is confirmed.
+	parentConfirmed := !(underAge && ps.Config().RequireParentEmail)
+
+	prefs := ps.Config().DefaultPreferences
+	prefs.DirectMail = reg.directMail
+
+	err = p.Register(reg.name, reg.figure, reg.sex, reg.motto, reg.email, reg.birthday, hash, parentConfirmed, &prefs)
+	switch {
+	case err == player.ErrNameTaken:
+		p.Session.Send(messages.APPROVENAMEREPLY, messages.APPROVENAMEREPLY(NAMEALREADYRESERVED))
+	case err != nil:
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Registration failed, please try again."))
+	default:
+		p.Session.Send(messages.REGOK, messages.REGOK())
+	}
+}
+
+// COPPA_REG_CHECKTIME tells the registration module whether the birthday the player entered makes them under age.
+func COPPA_REG_CHECKTIME(p *player.Player, packet *packets.IncomingPacket) {
+	birthday, err := time.Parse(BIRTHDAYFORMAT, packet.ReadString())
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid birthday."))
+		return
+	}
+
+	p.Session.Send(messages.COPPA_CHECKTIME, messages.COPPA_CHECKTIME(p.Services.PlayerService().UnderAge(birthday)))
+}
+
+// COPPA_REG_GETREALTIME sends the server's date so that ages aren't worked out with the client's clock.
+func COPPA_REG_GETREALTIME(p *player.Player, packet *packets.IncomingPacket) {
+	p.Session.Send(messages.COPPA_GETREALTIME, messages.COPPA_GETREALTIME(time.Now().Format(BIRTHDAYFORMAT)))
+}
+
+// PARENT_EMAIL_REQUIRED tells the registration module whether a player with the given birthday has to give their
+// parent's email address.
+func PARENT_EMAIL_REQUIRED(p *player.Player, packet *packets.IncomingPacket) {
+	birthday, err := time.Parse(BIRTHDAYFORMAT, packet.ReadString())
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid birthday."))
+		return
+	}
+
+	ps := p.Services.PlayerService()
+	required := ps.Config().RequireParentEmail && ps.UnderAge(birthday)
+	p.Session.Send(messages.PARENT_EMAIL_REQUIRED, messages.PARENT_EMAIL_REQUIRED(required))
+}
+
+// VALIDATE_PARENT_EMAIL checks the parent email address entered in the registration module.
+func VALIDATE_PARENT_EMAIL(p *player.Player, packet *packets.IncomingPacket) {
+	_, err := mail.ParseAddress(packet.ReadString())
+	p.Session.Send(messages.VALIDATE_PARENT_EMAIL, messages.VALIDATE_PARENT_EMAIL(err == nil))
+}
 
-	// hash password before storing in db
-	randSalt := crypto.GenerateRandomSalt(crypto.SALTSIZE)
-	hPsswrd := crypto.HashPassword(password, randSalt)
-
-	// generate date time stamp in UTC with format YYYY-MM-DD T HH-MM-SS
-	loc, _ := time.LoadLocation("UTC")
-	now := time.Now().In(loc)
-	createdAt := now.Format("2006-01-02 15:04:05")
-
-	// put birthday in YYYY-MM-DD format before storing in db
-	var bday string
-	for i, s := range strings.Split(birthday, ".") {
-		if i == 0 {
-			bday = s + bday
-		} else {
-			bday = s + "-" + bday
+// SEND_PARENT_EMAIL is sent by a restricted player once they've logged in, emailing their parent a link that
+// confirms the account.
+func SEND_PARENT_EMAIL(p *player.Player, packet *packets.IncomingPacket) {
+	if !p.Restricted() {
+		return
+	}
+
+	parentEmail := packet.ReadString()
+	if _, err := mail.ParseAddress(parentEmail); err != nil {
+		p.Session.Send(messages.VALIDATE_PARENT_EMAIL, messages.VALIDATE_PARENT_EMAIL(false))
+		return
+	}
+
+	ps := p.Services.PlayerService()
+	if !ps.AllowParentEmail(p.Session.Address()) {
+		p.Session.Send(messages.ALERT, messages.ALERT("Please wait a while before sending another email."))
+		return
+	}
+
+	switch ps.RequestParentConfirmation(p.Details.Id, p.Details.Username, parentEmail) {
+	case nil:
+		p.Session.Send(messages.ALERT, messages.ALERT("We've emailed your parent or guardian a link to confirm your account."))
+	case player.ErrConfirmationPending:
+		p.Session.Send(messages.ALERT, messages.ALERT(
+			"Your parent or guardian has already been emailed a link to confirm your account that can still be used."))
+	default:
+		p.Session.Send(messages.ALERT, messages.ALERT("The email couldn't be sent, please try again later."))
+	}
+}
+
+// restricted reports whether the player's account is waiting for a parent to confirm it, alerting them if it is.
+func restricted(p *player.Player) bool {
+	if !p.Restricted() {
+		return false
+	}
+	p.Session.Send(messages.ALERT, messages.ALERT(
+		"Your parent or guardian needs to confirm your account before you can do that."))
+	return true
+}
+
+// UPDATE updates the player's figure, sex & motto from the profile editor. Email & password changes are also
+// accepted here as long as the player's current password is sent along with them.
+func UPDATE(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 || p.Restricted() {
+		return
+	}
+
+	fields, err := readRegFields(packet)
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid profile update."))
+		return
+	}
+
+	figure, sex, motto := p.Details.Figure, p.Details.Sex, p.Details.Motto
+	if f, ok := fields.String(regFigure); ok {
+		figure = f
+	}
+	if s, ok := fields.String(regSex); ok {
+		sex = strings.ToUpper(s)
+	}
+	if m, ok := fields.String(regCustomData); ok {
+		motto = text.Filter(m)
+	}
+
+	if errMsg := checkProfile(p, figure, sex, motto); errMsg != "" {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR(errMsg))
+		return
+	}
+
+	_, emailSent := fields.String(regEmail)
+	_, passwordSent := fields.String(regPassword)
+	if emailSent || passwordSent {
+		if !updateAccount(p, fields) {
+			return
 		}
 	}
 
-	p.Register(username, figure, gender, email, bday, createdAt, hPsswrd, randSalt)
-	/*
-		2021/09/16 22:28:48 [127.0.0.1] [UNK] [@k - 43]: @B@Itreebeard@D@Y1000118001270012900121001@E@AM@F@@@G@Mboob@none.com@H@J27.01.1995@JA@A@@I@@C@Jtreebeard1
-		2021/09/16 22:29:04 [127.0.0.1] [INCOMING] [TRY_LOGIN - @D|4]: @Itreebeard@Jtreebeard1
-	*/
+	if err := player.UpdateProfile(p, figure, sex, motto); err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Failed to update your profile."))
+		return
+	}
+
+	if directMail, ok := fields.Bool(regDirectMail); ok && p.Preferences != nil && directMail != p.Preferences.DirectMail {
+		p.Preferences.DirectMail = directMail
+		_ = p.SavePreferences()
+	}
+
+	p.Session.Send(messages.UPDATEOK, messages.UPDATEOK())
+	p.Session.Send(messages.USEROBJ, messages.USEROBJ(p))
+}
+
+// UPDATE_ACCOUNT changes the player's email address and/or password after verifying their current password
+// and, if sent, their birthday.
+func UPDATE_ACCOUNT(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 || p.Restricted() {
+		return
+	}
+
+	fields, err := readRegFields(packet)
+	if err != nil {
+		p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Invalid account update."))
+		return
+	}
+
+	if updateAccount(p, fields) {
+		p.Session.Send(messages.UPDATE_ACCOUNT, messages.UPDATE_ACCOUNT(ACCOUNTUPDATED))
+	}
+}
+
+// updateAccount verifies the player's current password & birthday and then stores the new email address and/or
+// password found in fields. The player is sent the reason if anything fails & false is returned.
+func updateAccount(p *player.Player, fields *regFields) bool {
+	oldPassword, _ := fields.String(regOldPassword)
+	if !player.CheckPassword(p, oldPassword) {
+		p.Session.Send(messages.UPDATE_ACCOUNT, messages.UPDATE_ACCOUNT(INCORRECTPASSWORD))
+		return false
+	}
+
+	if birthday, ok := fields.String(regBirthday); ok {
+		registered, err := player.Birthday(p)
+		if err != nil || registered.Format(BIRTHDAYFORMAT) != birthday {
+			p.Session.Send(messages.UPDATE_ACCOUNT, messages.UPDATE_ACCOUNT(INCORRECTBIRTHDAY))
+			return false
+		}
+	}
+
+	email, emailSent := fields.String(regEmail)
+	if emailSent {
+		if _, err := mail.ParseAddress(email); err != nil {
+			p.Session.Send(messages.EMAIL_REJECTED, messages.EMAIL_REJECTED())
+			return false
+		}
+	}
+
+	password, passwordSent := fields.String(regPassword)
+	if passwordSent {
+		if code := checkPassword(p, p.Details.Username, password); code != OK {
+			p.Session.Send(messages.PASSWORD_APPROVED, messages.PASSWORD_APPROVED(code))
+			return false
+		}
+	}
+
+	if emailSent {
+		if err := player.UpdateEmail(p, email); err != nil {
+			p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Failed to update your email."))
+			return false
+		}
+	}
+
+	if passwordSent {
+		hasher := p.Services.PlayerService().PasswordHasher()
+		if err := player.UpdatePasswordHash(p, p.Details.Id, hasher, password); err != nil {
+			p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Failed to update your password."))
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkProfile validates a player's figure, sex & motto and returns the reason they were rejected,
+// or an empty string if they are acceptable.
+func checkProfile(p *player.Player, figure, sex, motto string) string {
+	switch {
+	case sex != "M" && sex != "F":
+		return "Invalid sex."
+	case p.Services.PlayerService().Figures().Validate(figure, sex, p.ClubMember()) != nil:
+		return "Invalid figure."
+	case utf8.RuneCountInString(motto) > MAXMOTTOLENGTH:
+		return "Your motto is too long."
+	default:
+		return ""
+	}
 }
 
 // checkName takes in a proposed username and returns an integer representing the approval status of the given name
@@ -124,16 +364,16 @@ func checkName(p *player.Player, username string) int {
 
 // checkPassword takes in a proposed password and returns an integer representing the approval status of the given password
 func checkPassword(p *player.Player, username, password string) int {
-	switch {
-	case len(password) < 6:
-		return PASSWORDTOOSHORT // too short
-	case len(password) > 16:
-		return PASSWORDTOOLONG // too long
-	case !text.ContainsAllowedChars(strings.ToLower(password), ALLOWEDCHARS) || strings.Contains(username, " "):
+	switch player.ValidatePassword(username, password) {
+	case player.ErrPasswordTooShort:
+		return PASSWORDTOOSHORT
+	case player.ErrPasswordTooLong:
+		return PASSWORDTOOLONG
+	case player.ErrPasswordUnacceptable:
 		return PASSWORDUNACCEPTABLE // using non-permitted characters
-	case !text.ContainsANumber(password):
-		return PASSWORDHASNONUM // password does not contain a number
-	case username == password:
+	case player.ErrPasswordHasNoNumber:
+		return PASSWORDHASNONUM
+	case player.ErrPasswordSimilarToName:
 		return PASSWORDSIMILARTONAME // name and pass too similar
 	default:
 		return OK
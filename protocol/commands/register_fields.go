@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jtieri/habbgo/protocol/encoding"
+	"github.com/jtieri/habbgo/protocol/packets"
+	"github.com/jtieri/habbgo/text"
+)
+
+// regField is the ID the registration module gives each field in the REGISTER, UPDATE and UPDATE_ACCOUNT packets.
+// Each field is sent as its Base64 encoded ID followed by its value, in no particular order.
+type regField int
+
+const (
+	regParentAgree      regField = 1
+	regName             regField = 2
+	regPassword         regField = 3
+	regFigure           regField = 4
+	regSex              regField = 5
+	regCustomData       regField = 6 // the player's motto
+	regEmail            regField = 7
+	regBirthday         regField = 8
+	regDirectMail       regField = 9
+	regHasReadAgreement regField = 10
+	regIspId            regField = 11
+	regPartnerSite      regField = 12
+	regOldPassword      regField = 13
+)
+
+// regBoolFields are sent as a single Base64 digit, @ for false & A for true, every other field is a string.
+var regBoolFields = map[regField]bool{
+	regParentAgree:      true,
+	regDirectMail:       true,
+	regHasReadAgreement: true,
+}
+
+// regFields holds the fields read from a registration module packet.
+type regFields struct {
+	strings map[regField]string
+	bools   map[regField]bool
+}
+
+// readRegFields reads every field left in the packet. Unknown field IDs are an error since there is no way to
+// tell how long their value is.
+func readRegFields(packet *packets.IncomingPacket) (*regFields, error) {
+	fields := &regFields{
+		strings: make(map[regField]string),
+		bools:   make(map[regField]bool),
+	}
+
+	for packet.Payload.Len() >= 2 {
+		id := regField(packet.ReadB64())
+
+		switch {
+		case id < regParentAgree || id > regOldPassword:
+			return nil, fmt.Errorf("unknown registration field %d", id)
+		case regBoolFields[id]:
+			value := packet.ReadBytes(1)
+			if len(value) != 1 {
+				return nil, fmt.Errorf("registration field %d is missing its value", id)
+			}
+			fields.bools[id] = encoding.DecodeB64(value) == 1
+		default:
+			if packet.Payload.Len() < 2 {
+				return nil, fmt.Errorf("registration field %d is missing its value", id)
+			}
+			fields.strings[id] = packet.ReadString()
+		}
+	}
+
+	return fields, nil
+}
+
+// String returns the value of a string field and whether it was sent.
+func (f *regFields) String(id regField) (string, bool) {
+	s, ok := f.strings[id]
+	return s, ok
+}
+
+// Bool returns the value of a boolean field and whether it was sent.
+func (f *regFields) Bool(id regField) (bool, bool) {
+	b, ok := f.bools[id]
+	return b, ok
+}
+
+var (
+	errInvalidEmail    = errors.New("invalid email address")
+	errInvalidBirthday = errors.New("invalid birthday")
+	errInvalidSex      = errors.New("invalid sex")
+)
+
+// registration holds the fields of a REGISTER packet that are stored for a new player.
+type registration struct {
+	name        string
+	password    string
+	figure      string
+	sex         string
+	motto       string
+	email       string
+	birthday    time.Time
+	directMail  bool
+	parentAgree bool
+}
+
+// newRegistration takes the fields of a REGISTER packet and checks that every required field was sent and that the
+// email address, birthday & sex are well formed. Checks that need the database, like whether the name is taken,
+// are left to the caller.
+func newRegistration(fields *regFields, now time.Time) (*registration, error) {
+	reg := &registration{}
+
+	for id, dst := range map[regField]*string{
+		regName:     &reg.name,
+		regPassword: &reg.password,
+		regFigure:   &reg.figure,
+		regSex:      &reg.sex,
+		regEmail:    &reg.email,
+	} {
+		value, ok := fields.String(id)
+		if !ok {
+			return nil, fmt.Errorf("registration field %d is missing", id)
+		}
+		*dst = value
+	}
+
+	motto, _ := fields.String(regCustomData)
+	reg.motto = text.Filter(motto)
+	reg.directMail, _ = fields.Bool(regDirectMail)
+	reg.parentAgree, _ = fields.Bool(regParentAgree)
+
+	reg.sex = strings.ToUpper(reg.sex)
+	if reg.sex != "M" && reg.sex != "F" {
+		return nil, errInvalidSex
+	}
+
+	if _, err := mail.ParseAddress(reg.email); err != nil {
+		return nil, errInvalidEmail
+	}
+
+	birthday, ok := fields.String(regBirthday)
+	if !ok {
+		return nil, errInvalidBirthday
+	}
+	bday, err := time.Parse(BIRTHDAYFORMAT, birthday)
+	if err != nil || bday.After(now) || bday.Year() < 1900 {
+		return nil, errInvalidBirthday
+	}
+	reg.birthday = bday
+
+	return reg, nil
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jtieri/habbgo/protocol/packets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRegFields(t *testing.T) {
+	// REGISTER packet captured from the v14 client, minus its header.
+	payload := "@B@Itreebeard@D@Y1000118001270012900121001@E@AM@F@@@G@Mboob@none.com@H@J27.01.1995@JA@A@@I@@C@Jtreebeard1"
+	packet := packets.NewIncoming([]byte("@k"), bytes.NewBufferString(payload))
+
+	fields, err := readRegFields(packet)
+	require.NoError(t, err)
+
+	for id, want := range map[regField]string{
+		regName:       "treebeard",
+		regFigure:     "1000118001270012900121001",
+		regSex:        "M",
+		regCustomData: "",
+		regEmail:      "boob@none.com",
+		regBirthday:   "27.01.1995",
+		regPassword:   "treebeard1",
+	} {
+		got, ok := fields.String(id)
+		require.True(t, ok, "field %d", id)
+		require.Equal(t, want, got)
+	}
+
+	for id, want := range map[regField]bool{regHasReadAgreement: true, regParentAgree: false, regDirectMail: false} {
+		got, ok := fields.Bool(id)
+		require.True(t, ok, "field %d", id)
+		require.Equal(t, want, got)
+	}
+
+	_, ok := fields.String(regOldPassword)
+	require.False(t, ok)
+}
+
+func TestReadRegFieldsRejectsUnknownFields(t *testing.T) {
+	packet := packets.NewIncoming([]byte("@k"), bytes.NewBufferString("@B@Itreebeard@Z@Cabc"))
+	_, err := readRegFields(packet)
+	require.Error(t, err)
+
+	packet = packets.NewIncoming([]byte("@k"), bytes.NewBufferString("@B@Itreebeard@J"))
+	_, err = readRegFields(packet)
+	require.Error(t, err)
+}
+
+func TestNewRegistration(t *testing.T) {
+	now := time.Date(2021, 9, 16, 0, 0, 0, 0, time.UTC)
+	read := func(payload string) *regFields {
+		fields, err := readRegFields(packets.NewIncoming([]byte("@k"), bytes.NewBufferString(payload)))
+		require.NoError(t, err)
+		return fields
+	}
+
+	reg, err := newRegistration(read(
+		"@B@Itreebeard@D@Y1000118001270012900121001@E@Am@F@@@G@Mboob@none.com@H@J27.01.1995@JA@A@@I@@C@Jtreebeard1"), now)
+	require.NoError(t, err)
+	require.Equal(t, "treebeard", reg.name)
+	require.Equal(t, "M", reg.sex)
+	require.Equal(t, time.Date(1995, 1, 27, 0, 0, 0, 0, time.UTC), reg.birthday)
+
+	_, err = newRegistration(read("@B@Itreebeard@D@Y1000118001270012900121001@E@AM@G@Mboob@none.com@H@J27.01.1995"), now)
+	require.Error(t, err) // no password
+
+	_, err = newRegistration(read(
+		"@B@Itreebeard@D@Y1000118001270012900121001@E@AX@G@Mboob@none.com@H@J27.01.1995@C@Jtreebeard1"), now)
+	require.ErrorIs(t, err, errInvalidSex)
+
+	_, err = newRegistration(read(
+		"@B@Itreebeard@D@Y1000118001270012900121001@E@AM@G@Mboob.none.com@H@J27.01.1995@C@Jtreebeard1"), now)
+	require.ErrorIs(t, err, errInvalidEmail)
+
+	_, err = newRegistration(read(
+		"@B@Itreebeard@D@Y1000118001270012900121001@E@AM@G@Mboob@none.com@H@J27.01.2095@C@Jtreebeard1"), now)
+	require.ErrorIs(t, err, errInvalidBirthday)
+}
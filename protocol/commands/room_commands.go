@@ -0,0 +1,301 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jtieri/habbgo/game/fuse"
+	"github.com/jtieri/habbgo/game/navigator"
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/game/room"
+	"github.com/jtieri/habbgo/protocol/messages"
+	"github.com/jtieri/habbgo/protocol/packets"
+)
+
+// CREATEFLAT creates a guest room owned by the player.
+func CREATEFLAT(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 || restricted(p) {
+		return
+	}
+
+	flat, err := parseNewFlat(packet.Text())
+	if err != nil || !room.GuestModel(flat.model) {
+		return
+	}
+	if room.SpecialModel(flat.model) && !p.HasRight(fuse.SpecialRoomLayouts) {
+		p.Session.Send(messages.ALERT, messages.ALERT("Only Habbo Club members can use this room layout."))
+		return
+	}
+
+	rs := p.Services.RoomService()
+	model := rs.Model(flat.model)
+	if model == nil {
+		return
+	}
+
+	r := room.NewRoom()
+	r.Model = model
+	r.Details.CategoryID = room.DefaultCategoryId
+	r.Details.Name = flat.name
+	r.Details.OwnerId = p.Details.Id
+	r.Details.OwnerName = p.Details.Username
+	r.Details.AccessType = flat.access
+	r.Details.ShowOwner = flat.showOwner
+	r.Details.MaxVisitors = room.DefaultMaxVisitors
+
+	max := p.Services.PlayerService().Config().MaxRoomsPerPlayer
+	switch err := rs.CreateRoom(r, max); err {
+	case nil:
+		p.Session.Send(messages.FLATCREATED, messages.FLATCREATED(r))
+	case room.ErrTooManyRooms:
+		p.Session.Send(messages.ALERT, messages.ALERT(fmt.Sprintf("You can't have more than %d rooms.", max)))
+	default:
+		p.Session.Send(messages.ALERT, messages.ALERT("Your room couldn't be created, please try again later."))
+	}
+}
+
+// GETFLATINFO sends the settings of a guest room.
+func GETFLATINFO(p *player.Player, packet *packets.IncomingPacket) {
+	roomId, err := flatId(packet.Text())
+	if err != nil {
+		return
+	}
+
+	r := p.Services.RoomService().LoadRoom(roomId)
+	if r == nil || p.Services.RoomService().PublicRoom(r) || !canSeeRoom(p, r) {
+		return
+	}
+
+	trading := false
+	if category := p.Services.NavigatorService().CategoryById(r.Details.CategoryID); category != nil {
+		trading = category.IsTrading
+	}
+	p.Session.Send(messages.FLATINFO, messages.FLATINFO(p, r, trading))
+}
+
+// UPDATEFLAT changes the name, access & owner visibility of a room the player controls.
+func UPDATEFLAT(p *player.Player, packet *packets.IncomingPacket) {
+	if restricted(p) {
+		return
+	}
+
+	update, err := parseFlatUpdate(packet.Text())
+	if err != nil {
+		return
+	}
+
+	r := controlledRoom(p, update.roomId)
+	if r == nil {
+		return
+	}
+
+	r.Details.Name = update.name
+	r.Details.AccessType = update.access
+	r.Details.ShowOwner = update.showOwner
+	_ = p.Services.RoomService().SaveRoom(r)
+}
+
+// SETFLATINFO changes the description, password, rights & visitor limit of a room the player controls.
+func SETFLATINFO(p *player.Player, packet *packets.IncomingPacket) {
+	if restricted(p) {
+		return
+	}
+
+	settings, err := parseFlatSettings(packet.Text())
+	if err != nil {
+		return
+	}
+
+	r := controlledRoom(p, settings.roomId)
+	if r == nil {
+		return
+	}
+
+	if settings.description != nil {
+		r.Details.Description = *settings.description
+	}
+	if settings.password != nil {
+		r.Details.Password = *settings.password
+	}
+	if settings.superUsers != nil {
+		r.Details.SudoUsers = *settings.superUsers
+	}
+	if settings.maxVisitors != nil {
+		r.Details.MaxVisitors = *settings.maxVisitors
+	}
+	_ = p.Services.RoomService().SaveRoom(r)
+}
+
+// DELETEFLAT deletes a room the player controls.
+func DELETEFLAT(p *player.Player, packet *packets.IncomingPacket) {
+	roomId, err := flatId(packet.Text())
+	if err != nil {
+		return
+	}
+
+	r := controlledRoom(p, roomId)
+	if r == nil {
+		return
+	}
+
+	ps := p.Services.PlayerService()
+	for _, occupant := range ps.PlayersInRoom(r.Details.Id) {
+		ps.LeaveRoom(occupant)
+		occupant.Session.Send(messages.HOTEL_VIEW, messages.HOTEL_VIEW())
+	}
+	_ = p.Services.RoomService().DeleteRoom(r)
+}
+
+// GETUSERFLATCATS lists the categories the player may put their rooms in.
+func GETUSERFLATCATS(p *player.Player, packet *packets.IncomingPacket) {
+	var categories []navigator.Category
+	for _, category := range p.Services.NavigatorService().FlatCategories() {
+		if p.CanSetFlatCategory(&category) {
+			categories = append(categories, category)
+		}
+	}
+	p.Session.Send(messages.USERFLATCATS, messages.USERFLATCATS(categories))
+}
+
+// GETFLATCAT sends the category of a guest room.
+func GETFLATCAT(p *player.Player, packet *packets.IncomingPacket) {
+	r := p.Services.RoomService().LoadRoom(packet.ReadInt())
+	if r == nil || p.Services.RoomService().PublicRoom(r) || !canSeeRoom(p, r) {
+		return
+	}
+	p.Session.Send(messages.FLATCAT, messages.FLATCAT(r.Details.Id, r.Details.CategoryID))
+}
+
+// SETFLATCAT moves a room the player controls to a category they may put rooms in. A loaded room is changed in place
+// and navigator listings are read from the database, so the room is listed in its new category straight away.
+func SETFLATCAT(p *player.Player, packet *packets.IncomingPacket) {
+	roomId := packet.ReadInt()
+	categoryId := packet.ReadInt()
+
+	r := controlledRoom(p, roomId)
+	if r == nil || r.Details.CategoryID == categoryId {
+		return
+	}
+
+	category := p.Services.NavigatorService().CategoryById(categoryId)
+	if category == nil || !p.CanSetFlatCategory(category) {
+		return
+	}
+
+	r.Details.CategoryID = category.ID
+	_ = p.Services.RoomService().SaveRoom(r)
+}
+
+// controlledRoom returns the guest room with the given id if the player owns it or may control any room, or nil.
+func controlledRoom(p *player.Player, roomId int) *room.Room {
+	if p.Details.Id == 0 {
+		return nil
+	}
+
+	r := p.Services.RoomService().LoadRoom(roomId)
+	if r == nil || p.Services.RoomService().PublicRoom(r) {
+		return nil
+	}
+	if r.Details.OwnerId != p.Details.Id && !p.HasRight(fuse.AnyRoomController) {
+		return nil
+	}
+	return r
+}
+
+// TRYFLAT asks to enter a guest room, sending the room's password along if it has one. Closed rooms can only be
+// entered by their owner & staff until doorbells are handled.
+func TRYFLAT(p *player.Player, packet *packets.IncomingPacket) {
+	if p.Details.Id == 0 {
+		return
+	}
+
+	data := strings.SplitN(packet.Text(), "/", 2)
+	roomId, err := strconv.Atoi(data[0])
+	if err != nil {
+		return
+	}
+
+	r := p.Services.RoomService().LoadRoom(roomId)
+	if r == nil || p.Services.RoomService().PublicRoom(r) || !canEnterRoom(p, r) {
+		return
+	}
+
+	if r.Details.OwnerId != p.Details.Id && !p.HasRight(fuse.AnyRoomController) {
+		if full(p, r) {
+			p.Session.Send(messages.CANTCONNECT, messages.CANTCONNECT(messages.CantConnectFull))
+			return
+		}
+
+		switch r.Details.AccessType {
+		case room.Closed:
+			if !p.HasRight(fuse.EnterLockedRooms) {
+				p.Session.Send(messages.CANTCONNECT, messages.CANTCONNECT(messages.CantConnectClosed))
+				return
+			}
+		case room.Password:
+			if len(data) < 2 || data[1] != r.Details.Password {
+				p.Session.Send(messages.LOCALISED_ERROR, messages.LOCALISED_ERROR("Incorrect flat password"))
+				return
+			}
+		}
+	}
+
+	p.Services.PlayerService().LetInto(p, r.Details.Id)
+	p.Session.Send(messages.FLAT_LETIN, messages.FLAT_LETIN())
+}
+
+// GOTOFLAT enters the guest room TRYFLAT let the player into.
+func GOTOFLAT(p *player.Player, packet *packets.IncomingPacket) {
+	roomId, err := strconv.Atoi(strings.TrimSpace(packet.Text()))
+	if err != nil {
+		return
+	}
+	p.Services.PlayerService().EnterLetInRoom(p, roomId)
+}
+
+// ROOM_DIRECTORY enters a public room, guest rooms are entered through TRYFLAT & GOTOFLAT so the client is only told
+// to go ahead.
+func ROOM_DIRECTORY(p *player.Player, packet *packets.IncomingPacket) {
+	public := string(packet.ReadBytes(1)) == "A"
+	roomId := packet.ReadInt()
+
+	if !public {
+		p.Session.Send(messages.OPC_OK, messages.OPC_OK())
+		return
+	}
+
+	if roomId >= room.PublicRoomOffset {
+		roomId -= room.PublicRoomOffset
+	}
+	r := p.Services.RoomService().LoadRoom(roomId)
+	if r == nil || !p.Services.RoomService().PublicRoom(r) || !canSeeRoom(p, r) || !canEnterRoom(p, r) {
+		return
+	}
+
+	if full(p, r) {
+		p.Session.Send(messages.CANTCONNECT, messages.CANTCONNECT(messages.CantConnectFull))
+		return
+	}
+	p.Services.PlayerService().EnterRoom(p, r.Details.Id)
+}
+
+// QUIT leaves the room the player is in.
+func QUIT(p *player.Player, packet *packets.IncomingPacket) {
+	p.Services.PlayerService().LeaveRoom(p)
+}
+
+// canEnterRoom reports whether the player may enter the room, alerting them if it's for club members only.
+func canEnterRoom(p *player.Player, r *room.Room) bool {
+	if p.CanEnterRoom(r) {
+		return true
+	}
+	p.Session.Send(messages.ALERT, messages.ALERT("Only Habbo Club members can visit these rooms."))
+	return false
+}
+
+// full reports whether the room has no space left for the player, staff with fuse.EnterFullRooms always fit.
+func full(p *player.Player, r *room.Room) bool {
+	visitors := len(p.Services.PlayerService().PlayersInRoom(r.Details.Id))
+	return visitors >= r.Details.MaxVisitors && !p.HasRight(fuse.EnterFullRooms)
+}
@@ -11,12 +11,17 @@ import "math"
 
 // EncodeB64 takes an integer, encodes it in FUSE-Base64 & returns a slice of, length number of, bytes.
 func EncodeB64(i int, length int) []byte {
-	bytes := make([]byte, length)
+	return AppendB64(make([]byte, 0, length), i, length)
+}
+
+// AppendB64 encodes an integer in FUSE-Base64 & appends the length number of bytes to dst,
+// returning the extended slice.
+func AppendB64(dst []byte, i int, length int) []byte {
 	for j := 1; j <= length; j++ {
 		k := uint((length - j) * 6)
-		bytes[j-1] = byte(0x40 + ((i >> k) & 0x3f))
+		dst = append(dst, byte(0x40+((i>>k)&0x3f)))
 	}
-	return bytes
+	return dst
 }
 
 // DecodeB64 take a slice of bytes, decodes it from FUSE-Base64 & returns the decoded bytes as an integer.
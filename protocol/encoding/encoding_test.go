@@ -0,0 +1,62 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVl64RoundTrip(t *testing.T) {
+	for _, i := range []int{0, 1, -1, 3, 4, 63, 64, 255, 256, 4095, -4096, 1 << 20, -(1 << 24), 2147483647} {
+		require.Equal(t, i, DecodeVl64(EncodeVl64(i)))
+		require.Equal(t, EncodeVl64(i), AppendVl64(nil, i))
+	}
+
+	require.Equal(t, []byte("H"), EncodeVl64(0))
+	require.Equal(t, []byte("I"), EncodeVl64(1))
+	require.Equal(t, []byte("@@K"), AppendVl64([]byte("@@"), 3))
+}
+
+func TestB64RoundTrip(t *testing.T) {
+	for i := 0; i < 4096; i++ {
+		require.Equal(t, i, DecodeB64(EncodeB64(i, 2)))
+	}
+
+	require.Equal(t, []byte("C\\"), EncodeB64(220, 2))
+	require.Equal(t, []byte("x@A"), AppendB64([]byte("x"), 1, 2))
+}
+
+// sink keeps the benchmarked results alive so the compiler can't optimize the encoding away.
+var sink []byte
+
+func BenchmarkEncodeVl64(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink = EncodeVl64(i)
+	}
+}
+
+func BenchmarkAppendVl64(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 6)
+	for i := 0; i < b.N; i++ {
+		buf = AppendVl64(buf[:0], i)
+	}
+	sink = buf
+}
+
+func BenchmarkEncodeB64(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink = EncodeB64(i, 2)
+	}
+}
+
+func BenchmarkAppendB64(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 2)
+	for i := 0; i < b.N; i++ {
+		buf = AppendB64(buf[:0], i, 2)
+	}
+	sink = buf
+}
@@ -27,7 +27,13 @@ func DecodeVl64(input []byte) int {
 // EncodeVl64 returns a slice of bytes capable of storing all increments.
 // Removes all @ symbols (padding) after last non-@ before returning.
 func EncodeVl64(input int) []byte {
-	vl64 := make([]byte, 6)              // 32-bit integer causes VL64 to have max length of 6.
+	return AppendVl64(make([]byte, 0, 6), input) // 32-bit integer causes VL64 to have max length of 6.
+}
+
+// AppendVl64 encodes input in Vl64 & appends the encoded bytes to dst, returning the extended slice.
+// Like EncodeVl64 all @ symbols (padding) after the last non-@ are left out.
+func AppendVl64(dst []byte, input int) []byte {
+	var vl64 [6]byte                     // 32-bit integer causes VL64 to have max length of 6.
 	num := int(math.Abs(float64(input))) // Operate on normalized, positive integer
 	length := 1                          // Length indicator, updated during encode
 
@@ -51,7 +57,7 @@ func EncodeVl64(input int) []byte {
 	}
 
 	vl64[0] = byte(int(vl64[0]) + length*8) // Base4 char shifted to indicate total length
-	return vl64[:length]                    // Last padding symbols trimmed out
+	return append(dst, vl64[:length]...)    // Last padding symbols trimmed out
 }
 
 // length returns the total length of the mixed radix number.
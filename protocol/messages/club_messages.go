@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"time"
+
+	"github.com/jtieri/habbgo/game/club"
+	"github.com/jtieri/habbgo/protocol/packets"
+)
+
+const ( // Used in SCR_SINFO
+	clubInfo      = 1 // reply to SCR_GET_USER_INFO
+	clubPurchased = 2 // reply to SCR_BUY, the client thanks the player for buying
+)
+
+func SCR_SINFO(s *club.Subscription, purchased bool) *packets.OutgoingPacket {
+	packet := packets.NewOutgoing(7) // Base64 Header @G
+
+	now := time.Now()
+	packet.WriteString("club_habbo")
+	packet.WriteInt(s.DaysLeft(now))
+	packet.WriteInt(s.ElapsedPeriods(now))
+	packet.WriteInt(s.PrepaidPeriods(now))
+	if purchased {
+		packet.WriteInt(clubPurchased)
+	} else {
+		packet.WriteInt(clubInfo)
+	}
+	return packet
+}
+
+func NO_CREDITS() *packets.OutgoingPacket {
+	return packets.NewOutgoing(68) // Base64 Header AD
+}
@@ -1,8 +1,12 @@
 package messages
 
 import (
-	"github.com/jtieri/habbgo/protocol/packets"
 	"strconv"
+	"strings"
+
+	"github.com/jtieri/habbgo/game/fuse"
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/protocol/packets"
 )
 
 const ( // Used in SESSIONPARAMETERS
@@ -39,19 +43,20 @@ func ENDCRYPTO() *packets.OutgoingPacket {
 	return packet
 }
 
-func SESSIONPARAMETERS() *packets.OutgoingPacket {
+func SESSIONPARAMETERS(config *player.Config) *packets.OutgoingPacket {
 	packet := packets.NewOutgoing(257) // Base64 Header DA
 
-	params := make(map[int]string, 9)
-	params[voucherEnabled] = strconv.Itoa(0) // TODO create config to enable if vouchers are enabled
-	params[registerRequireParentEmail] = strconv.Itoa(0)
-	params[registerSendParentEmail] = strconv.Itoa(0)
+	params := make(map[int]string, 10)
+	params[registerCoppa] = strconv.Itoa(config.Coppa)
+	params[voucherEnabled] = boolParam(config.VoucherEnabled)
+	params[registerRequireParentEmail] = boolParam(config.RequireParentEmail)
+	params[registerSendParentEmail] = boolParam(config.SendParentEmail)
 	params[allowDirectMail] = strconv.Itoa(0)
 	params[dateFormat] = "dd-MM-yyyy"
 	params[partnerIntegrationEnabled] = strconv.Itoa(0)
 	params[allowProfileEditing] = strconv.Itoa(1) // TODO create config to enable if profile editing is enabled
 	params[trackingHeader] = ""
-	params[tutorialEnabled] = strconv.Itoa(0) // TODO check if player has finished tutorial then set appropriately
+	params[tutorialEnabled] = strconv.Itoa(0) // the client's tutorial completion message isn't handled yet
 
 	packet.WriteInt(len(params))
 
@@ -68,24 +73,62 @@ func SESSIONPARAMETERS() *packets.OutgoingPacket {
 	return packet
 }
 
-func AVAILABLESETS() *packets.OutgoingPacket {
+func AVAILABLESETS(sets []int) *packets.OutgoingPacket {
 	packet := packets.NewOutgoing(8) // Base64 Header "@H"
-	// TODO make this a configurable option
-	packet.Write("[100,105,110,115,120,125,130,135,140,145,150,155,160,165,170,175,176,177,178,180,185,190,195,200,205,206,207,210,215,220,225,230,235,240,245,250,255,260,265,266,267,270,275,280,281,285,290,295,300,305,500,505,510,515,520,525,530,535,540,545,550,555,565,570,575,580,585,590,595,596,600,605,610,615,620,625,626,627,630,635,640,645,650,655,660,665,667,669,670,675,680,685,690,695,696,700,705,710,715,720,725,730,735,740]")
+
+	ids := make([]string, len(sets))
+	for i, id := range sets {
+		ids[i] = strconv.Itoa(id)
+	}
+	packet.Write("[" + strings.Join(ids, ",") + "]")
 	return packet
 }
 
+// boolParam formats a boolean SESSIONPARAMETERS value.
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
 func LOGINOK() *packets.OutgoingPacket {
 	packet := packets.NewOutgoing(3) // Base64 Header @C
 	return packet
 }
 
+func RIGHTS(rights []fuse.Right) *packets.OutgoingPacket {
+	packet := packets.NewOutgoing(2) // Base64 Header @B
+	for _, right := range rights {
+		packet.WriteString(string(right))
+	}
+	return packet
+}
+
 func LOCALISED_ERROR(errMsg string) *packets.OutgoingPacket {
 	packet := packets.NewOutgoing(33) // Base64 Header @a
 	packet.Write(errMsg)
 	return packet
 }
 
+func MACHINEID(machineId string) *packets.OutgoingPacket {
+	packet := packets.NewOutgoing(439) // Base64 Header Fw
+	packet.WriteString(machineId)
+	return packet
+}
+
+func ALERT(msg string) *packets.OutgoingPacket {
+	packet := packets.NewOutgoing(139) // Base64 Header BK
+	packet.WriteString(msg)
+	return packet
+}
+
+func USER_BANNED(banMsg string) *packets.OutgoingPacket {
+	packet := packets.NewOutgoing(35) // Base64 Header @c
+	packet.WriteString(banMsg)
+	return packet
+}
+
 func isNumber(s string) bool {
 	if _, err := strconv.Atoi(s); err == nil {
 		return true
@@ -1,10 +1,10 @@
 package messages
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/jtieri/habbgo/game/fuse"
 	"github.com/jtieri/habbgo/game/navigator"
 	"github.com/jtieri/habbgo/game/player"
 	"github.com/jtieri/habbgo/game/room"
@@ -34,61 +34,185 @@ func NAVNODEINFO(player *player.Player, parentCat *navigator.Category, hideFullR
 	}
 
 	for _, r := range rooms {
-		if r.Details.OwnerId == 0 { // if r is public
-			desc := r.Details.Description
-
-			var door int
-			if strings.Contains(desc, "/") {
-				data := strings.Split(desc, "/")
-				desc = data[0]
-				door, _ = strconv.Atoi(data[1])
-			}
-
-			p.WriteInt(r.Details.Id + room.PublicRoomOffset) // writeInt roomId
-			p.WriteInt(1)                                    // writeInt 1
-			p.WriteString(r.Details.Name)                    // writeString roomName
-			p.WriteInt(r.Details.CurrentVisitors)            // writeInt currentVisitors
-			p.WriteInt(r.Details.MaxVisitors)                // writeInt maxVisitors
-			p.WriteInt(r.Details.CategoryID)                 // writeInt catId
-			p.WriteString(desc)                              // writeString roomDesc
-			p.WriteInt(r.Details.Id)                         // writeInt roomId
-			p.WriteInt(door)                                 // writeInt door
-			p.WriteString(r.Details.CCTs)                    // writeString roomCCTs
-			p.WriteInt(0)                                    // writeInt 0
-			p.WriteInt(1)                                    // writeInt 1
-		} else {
-			p.WriteInt(r.Details.Id)
-			p.WriteString(r.Details.Name)
-
-			// TODO check that player is owner of r, that r is showing owner name, or that player has right SEE_ALL_ROOMOWNERS
-			if player.Details.Username == r.Details.OwnerName || r.Details.ShowOwner {
-				p.WriteString(r.Details.OwnerName)
-			} else {
-				p.WriteString("-")
-			}
-
-			p.WriteString(r.Details.AccessType.String())
-			p.WriteInt(r.Details.CurrentVisitors)
-			p.WriteInt(r.Details.MaxVisitors)
-			p.WriteString(r.Details.Description)
-		}
+		writeNavRoom(p, player, r)
 	}
 
 	// iterate over sub-categories
 	for _, subcat := range subcats {
-		if subcat.MinRankAccess > player.Details.PlayerRank {
+		if !player.HasRight(subcat.AccessRight) {
 			continue
 		}
 
-		r := player.Services.RoomService().Rooms()
-		fmt.Println(subcat.Name)
+		current, max := player.Services.RoomService().CategoryVisitors(subcat.ID)
 		p.WriteInt(subcat.ID)
 		p.WriteInt(0)
 		p.WriteString(subcat.Name)
-		p.WriteInt(navigator.CurrentVisitors(&subcat, r)) // writeInt currentVisitors
-		p.WriteInt(navigator.MaxVisitors(&subcat, r))     // writeInt maxVisitors
+		p.WriteInt(current) // writeInt currentVisitors
+		p.WriteInt(max)     // writeInt maxVisitors
 		p.WriteInt(parentCat.ID)
 	}
 
 	return p
 }
+
+// writeNavRoom writes a room the way the navigator lists it, public & guest rooms are written differently.
+func writeNavRoom(p *packets.OutgoingPacket, player *player.Player, r *room.Room) {
+	if r.Details.OwnerId == 0 { // if r is public
+		desc := r.Details.Description
+
+		var door int
+		if strings.Contains(desc, "/") {
+			data := strings.Split(desc, "/")
+			desc = data[0]
+			door, _ = strconv.Atoi(data[1])
+		}
+
+		p.WriteInt(r.Details.Id + room.PublicRoomOffset) // writeInt roomId
+		p.WriteInt(1)                                    // writeInt 1
+		p.WriteString(r.Details.Name)                    // writeString roomName
+		p.WriteInt(r.Details.CurrentVisitors)            // writeInt currentVisitors
+		p.WriteInt(r.Details.MaxVisitors)                // writeInt maxVisitors
+		p.WriteInt(r.Details.CategoryID)                 // writeInt catId
+		p.WriteString(desc)                              // writeString roomDesc
+		p.WriteInt(r.Details.Id)                         // writeInt roomId
+		p.WriteInt(door)                                 // writeInt door
+		p.WriteString(r.Details.CCTs)                    // writeString roomCCTs
+		p.WriteInt(0)                                    // writeInt 0
+		p.WriteInt(1)                                    // writeInt 1
+	} else {
+		p.WriteInt(r.Details.Id)
+		p.WriteString(r.Details.Name)
+
+		p.WriteString(ownerName(player, r))
+
+		p.WriteString(r.Details.AccessType.String())
+		p.WriteInt(r.Details.CurrentVisitors)
+		p.WriteInt(r.Details.MaxVisitors)
+		p.WriteString(r.Details.Description)
+	}
+}
+
+// FAVOURITE_ROOMS lists the player's favourite rooms like a navigator category, guest rooms are listed before public
+// rooms.
+func FAVOURITE_ROOMS(player *player.Player, rooms []*room.Room) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(61) // Base64 Header @}
+
+	var (
+		private, public []*room.Room
+		current, max    int
+	)
+	for _, r := range rooms {
+		current += r.Details.CurrentVisitors
+		max += r.Details.MaxVisitors
+		if r.Details.OwnerId == 0 {
+			public = append(public, r)
+		} else {
+			private = append(private, r)
+		}
+	}
+
+	p.WriteBool(false) // hideCategory
+	p.WriteInt(0)      // category id
+	p.WriteInt(2)      // listed like a guest room category
+	p.WriteString("")
+	p.WriteInt(current)
+	p.WriteInt(max)
+	p.WriteInt(0) // parent category id
+	p.WriteInt(len(private))
+
+	for _, r := range private {
+		writeNavRoom(p, player, r)
+	}
+	for _, r := range public {
+		writeNavRoom(p, player, r)
+	}
+
+	return p
+}
+
+func FLAT_RESULTS(player *player.Player, rooms []*room.Room) *packets.OutgoingPacket {
+	return flatResults(16, player, rooms) // Base64 Header @P
+}
+
+func SEARCH_FLAT_RESULTS(player *player.Player, rooms []*room.Room) *packets.OutgoingPacket {
+	return flatResults(55, player, rooms) // Base64 Header @w
+}
+
+// flatResults writes a tab separated line for each guest room, the client lists them in the navigator's search &
+// own rooms tabs.
+func flatResults(header int, player *player.Player, rooms []*room.Room) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(header)
+	for _, r := range rooms {
+		fields := []string{
+			strconv.Itoa(r.Details.Id),
+			r.Details.Name,
+			ownerName(player, r),
+			r.Details.AccessType.String(),
+			"x",
+			strconv.Itoa(r.Details.CurrentVisitors),
+			strconv.Itoa(r.Details.MaxVisitors),
+			"null",
+			r.Details.Description,
+		}
+		p.Write(strings.Join(fields, "\t") + "\t\r")
+	}
+	return p
+}
+
+func NOFLATSFORUSER(username string) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(57) // Base64 Header @y
+	p.Write(username)
+	return p
+}
+
+func NOFLATS() *packets.OutgoingPacket {
+	return packets.NewOutgoing(58) // Base64 Header @z
+}
+
+// ownerName returns the name of the room's owner to show the player, or "-" if the owner chose to hide it.
+func ownerName(player *player.Player, r *room.Room) string {
+	if player.Details.Id == r.Details.OwnerId || r.Details.ShowOwner || player.HasRight(fuse.SeeAllRoomOwners) {
+		return r.Details.OwnerName
+	}
+	return "-"
+}
+
+func USERFLATCATS(categories []navigator.Category) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(221) // Base64 Header C]
+	p.WriteInt(len(categories))
+	for _, c := range categories {
+		p.WriteInt(c.ID)
+		p.WriteString(c.Name)
+	}
+	return p
+}
+
+func FLATCAT(roomId, categoryId int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(222) // Base64 Header C^
+	p.WriteInt(roomId)
+	p.WriteInt(categoryId)
+	return p
+}
+
+// PARENTCHAIN sends a category followed by its ancestors, the client shows them as the navigator's breadcrumbs.
+func PARENTCHAIN(category *navigator.Category, parents []navigator.Category) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(227) // Base64 Header Cc
+	p.WriteInt(category.ID)
+	p.WriteString(category.Name)
+	for _, parent := range parents {
+		p.WriteInt(parent.ID)
+		p.WriteString(parent.Name)
+	}
+	return p
+}
+
+// SPACENODEUSERS lists the names of the players in a public room.
+func SPACENODEUSERS(nodeId int, usernames []string) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(223) // Base64 Header C_
+	p.WriteInt(nodeId)
+	p.WriteInt(len(usernames))
+	for _, name := range usernames {
+		p.WriteString(name)
+	}
+	return p
+}
@@ -3,6 +3,7 @@ package messages
 import (
 	"strconv"
 
+	"github.com/jtieri/habbgo/game/credits"
 	"github.com/jtieri/habbgo/game/player"
 	"github.com/jtieri/habbgo/protocol/packets"
 )
@@ -29,6 +30,60 @@ func CREDITBALANCE(credits int) *packets.OutgoingPacket {
 	return p
 }
 
+func TICKETS(tickets int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(72) // Base64 Header AH
+	p.WriteInt(tickets)
+	return p
+}
+
+func FILM(film int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(4) // Base64 Header @D
+	p.WriteInt(film)
+	return p
+}
+
+// NO_TICKETS tells the player they need tickets to play.
+func NO_TICKETS() *packets.OutgoingPacket {
+	return packets.NewOutgoing(73) // Base64 Header AI
+}
+
+// TICKETS_BUY confirms that the player bought tickets, with the number they bought.
+func TICKETS_BUY(amount int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(124) // Base64 Header A|
+	p.WriteInt(amount)
+	return p
+}
+
+func CREDITLOG(transactions []credits.Transaction) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(209) // Base64 Header CQ
+	for _, t := range transactions {
+		p.Write(t.CreatedAt.Format("02-01-2006") + "\t" + t.CreatedAt.Format("15:04") + "\t")
+		p.Write(strconv.Itoa(t.Amount) + "\t" + strconv.Itoa(t.Balance) + "\t\t")
+		p.Write(string(t.Reason))
+		if t.Reference != "" {
+			p.Write(" " + t.Reference)
+		}
+		p.Write("\r")
+	}
+	return p
+}
+
+// VOUCHER_REDEEM_OK tells the player their voucher was redeemed, listing the names of any furniture it gave them.
+func VOUCHER_REDEEM_OK(items []string) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(212) // Base64 Header CT
+	for _, item := range items {
+		p.WriteString(item)
+	}
+	return p
+}
+
+// VOUCHER_REDEEM_ERROR tells the player their voucher couldn't be redeemed, the client localises the error code.
+func VOUCHER_REDEEM_ERROR(code string) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(213) // Base64 Header CU
+	p.WriteString(code)
+	return p
+}
+
 func AVAILABLEBADGES(p *player.Player) *packets.OutgoingPacket {
 	packet := packets.NewOutgoing(229) // Base64 Header
 
@@ -48,6 +103,16 @@ func AVAILABLEBADGES(p *player.Player) *packets.OutgoingPacket {
 	return packet
 }
 
+// USERBADGE tells everyone in a room which badge a player in it is now wearing.
+func USERBADGE(unitId int, code string, visible bool) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(228) // Base64 Header Cd
+	p.WriteString(strconv.Itoa(unitId))
+	if visible && code != "" {
+		p.WriteString(code)
+	}
+	return p
+}
+
 func SOUNDSETTING(ss bool) *packets.OutgoingPacket {
 	p := packets.NewOutgoing(308) // Base 64 Header Dt
 	p.WriteBool(ss)
@@ -35,3 +35,43 @@ func EMAIL_REJECTED() *packets.OutgoingPacket {
 	p := packets.NewOutgoing(272) // Base64 Header -
 	return p
 }
+
+func UPDATEOK() *packets.OutgoingPacket {
+	p := packets.NewOutgoing(211) // Base64 Header CS
+	return p
+}
+
+func UPDATE_ACCOUNT(errorCode int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(169) // Base64 Header Bi
+	p.WriteInt(errorCode)
+	return p
+}
+
+func REGOK() *packets.OutgoingPacket {
+	p := packets.NewOutgoing(51) // Base64 Header @s
+	return p
+}
+
+func COPPA_CHECKTIME(underAge bool) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(214) // Base64 Header CV
+	p.WriteBool(underAge)
+	return p
+}
+
+func COPPA_GETREALTIME(date string) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(215) // Base64 Header CW
+	p.WriteString(date)
+	return p
+}
+
+func PARENT_EMAIL_REQUIRED(required bool) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(217) // Base64 Header CY
+	p.WriteBool(required)
+	return p
+}
+
+func VALIDATE_PARENT_EMAIL(valid bool) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(218) // Base64 Header CZ
+	p.WriteBool(valid)
+	return p
+}
@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"strconv"
+
+	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/game/room"
+	"github.com/jtieri/habbgo/protocol/packets"
+)
+
+func FLATCREATED(r *room.Room) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(59) // Base64 Header @{
+	p.Write(strconv.Itoa(r.Details.Id) + "\r" + r.Details.Name)
+	return p
+}
+
+// FLATINFO sends the settings of a guest room, trading tells the client whether the room's category allows trading.
+func FLATINFO(player *player.Player, r *room.Room, trading bool) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(54) // Base64 Header @v
+	p.WriteBool(r.Details.SudoUsers)
+	p.WriteInt(int(r.Details.AccessType))
+	p.WriteInt(r.Details.Id)
+	p.WriteString(ownerName(player, r))
+	p.WriteString(r.Model.Name)
+	p.WriteString(r.Details.Name)
+	p.WriteString(r.Details.Description)
+	p.WriteBool(r.Details.ShowOwner)
+	p.WriteBool(trading)
+	p.WriteBool(false) // alert
+	p.WriteInt(r.Details.CurrentVisitors)
+	p.WriteInt(r.Details.MaxVisitors)
+	return p
+}
+
+// FLAT_LETIN tells the client it may enter the guest room it asked about with TRYFLAT.
+func FLAT_LETIN() *packets.OutgoingPacket {
+	return packets.NewOutgoing(41) // Base64 Header @i
+}
+
+// OPC_OK tells the client to go ahead with entering a guest room.
+func OPC_OK() *packets.OutgoingPacket {
+	return packets.NewOutgoing(19) // Base64 Header @S
+}
+
+const ( // CANTCONNECT reasons
+	CantConnectFull   = 1
+	CantConnectClosed = 2
+)
+
+// CANTCONNECT tells the client it can't enter the room, and why.
+func CANTCONNECT(reason int) *packets.OutgoingPacket {
+	p := packets.NewOutgoing(224) // Base64 Header C`
+	p.WriteInt(reason)
+	return p
+}
+
+// HOTEL_VIEW sends the client out of its room, back to the hotel view.
+func HOTEL_VIEW() *packets.OutgoingPacket {
+	return packets.NewOutgoing(18) // Base64 Header @R
+}
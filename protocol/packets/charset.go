@@ -0,0 +1,140 @@
+package packets
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// UnmappablePolicy decides what happens to characters that can't be represented in a Charset when encoding.
+type UnmappablePolicy int
+
+const (
+	Replace UnmappablePolicy = iota // unrepresentable characters are replaced with '?'
+	Strip                           // unrepresentable characters are dropped from the string
+)
+
+// ParseUnmappablePolicy returns the UnmappablePolicy named by s, which is either "replace" or "strip".
+func ParseUnmappablePolicy(s string) (UnmappablePolicy, error) {
+	switch strings.ToLower(s) {
+	case "", "replace":
+		return Replace, nil
+	case "strip":
+		return Strip, nil
+	default:
+		return Replace, fmt.Errorf("unknown unmappable character policy %q", s)
+	}
+}
+
+// Charset transcodes strings between UTF-8, which is what habbgo uses internally and stores in the database,
+// and the single-byte character set spoken by the Director client.
+type Charset struct {
+	Name   string
+	Policy UnmappablePolicy
+
+	decode *[256]rune    // byte -> rune, nil for UTF-8
+	encode map[rune]byte // rune -> byte, nil for UTF-8
+}
+
+// cp1252High holds the Windows-1252 code points for bytes 0x80-0x9F, every other byte maps to the same code point
+// as in ISO-8859-1. The five bytes left undefined by Windows-1252 decode to their C1 control characters so that
+// they survive a round trip.
+var cp1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// NewCharset returns the Charset with the given name using policy p for unrepresentable characters.
+// Supported names are windows-1252 (cp1252), iso-8859-1 (latin1) and utf-8.
+func NewCharset(name string, p UnmappablePolicy) (*Charset, error) {
+	switch strings.ToLower(name) {
+	case "windows-1252", "cp1252":
+		return singleByte("windows-1252", p, cp1252High[:]), nil
+	case "iso-8859-1", "latin1":
+		return singleByte("iso-8859-1", p, nil), nil
+	case "utf-8", "utf8":
+		return &Charset{Name: "utf-8", Policy: p}, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", name)
+	}
+}
+
+// singleByte builds the lookup tables for a single-byte Charset that matches ISO-8859-1 outside of 0x80-0x9F.
+func singleByte(name string, p UnmappablePolicy, high []rune) *Charset {
+	c := &Charset{Name: name, Policy: p, decode: new([256]rune), encode: make(map[rune]byte, 256)}
+
+	for i := 0; i < 256; i++ {
+		r := rune(i)
+		if high != nil && i >= 0x80 && i <= 0x9F {
+			r = high[i-0x80]
+		}
+
+		c.decode[i] = r
+		c.encode[r] = byte(i)
+	}
+
+	return c
+}
+
+// Decode converts bytes received from the client into a UTF-8 string.
+func (c *Charset) Decode(b []byte) string {
+	if c.decode == nil {
+		return strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, x := range b {
+		sb.WriteRune(c.decode[x])
+	}
+	return sb.String()
+}
+
+// Encode converts a UTF-8 string into the bytes sent to the client, applying the Charset's UnmappablePolicy
+// to characters it can't represent.
+func (c *Charset) Encode(s string) []byte {
+	return c.AppendEncode(make([]byte, 0, len(s)), s)
+}
+
+// AppendEncode is like Encode but appends the encoded bytes to dst and returns the extended slice.
+func (c *Charset) AppendEncode(dst []byte, s string) []byte {
+	if c.encode == nil {
+		return append(dst, s...)
+	}
+
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			dst = append(dst, byte(r))
+			continue
+		}
+
+		if b, ok := c.encode[r]; ok {
+			dst = append(dst, b)
+		} else if c.Policy == Replace {
+			dst = append(dst, '?')
+		}
+	}
+	return dst
+}
+
+var (
+	charsetMux sync.RWMutex
+	charset, _ = NewCharset("windows-1252", Replace)
+)
+
+// SetCharset sets the Charset used by every IncomingPacket and OutgoingPacket to transcode strings.
+func SetCharset(c *Charset) {
+	charsetMux.Lock()
+	defer charsetMux.Unlock()
+	charset = c
+}
+
+// CurrentCharset returns the Charset used by packets to transcode strings, Windows-1252 by default.
+func CurrentCharset() *Charset {
+	charsetMux.RLock()
+	defer charsetMux.RUnlock()
+	return charset
+}
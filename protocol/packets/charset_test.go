@@ -0,0 +1,50 @@
+package packets
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jtieri/habbgo/protocol/encoding"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharsetWindows1252(t *testing.T) {
+	c, err := NewCharset("windows-1252", Replace)
+	require.NoError(t, err)
+
+	require.Equal(t, []byte("Andr\xe9 \x80 caf\xe9"), c.Encode("André € café"))
+	require.Equal(t, "André € café", c.Decode([]byte("Andr\xe9 \x80 caf\xe9")))
+	require.Equal(t, "“quoted”", c.Decode([]byte("\x93quoted\x94")))
+
+	// Every byte should survive a round trip, including the ones Windows-1252 leaves undefined.
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	require.Equal(t, all, c.Encode(c.Decode(all)))
+}
+
+func TestCharsetUnmappablePolicy(t *testing.T) {
+	replace, err := NewCharset("iso-8859-1", Replace)
+	require.NoError(t, err)
+	require.Equal(t, []byte("habbo ? \xe4"), replace.Encode("habbo € ä"))
+
+	strip, err := NewCharset("cp1252", Strip)
+	require.NoError(t, err)
+	require.Equal(t, []byte("habbo  \xe4"), strip.Encode("habbo ☃ ä"))
+
+	_, err = ParseUnmappablePolicy("explode")
+	require.Error(t, err)
+	_, err = NewCharset("ebcdic", Replace)
+	require.Error(t, err)
+}
+
+func TestPacketStringsAreTranscoded(t *testing.T) {
+	out := NewOutgoing(5)
+	out.WriteString("Motto ünd €")
+	require.Equal(t, "@EMotto \xfcnd \x80\x02", out.String())
+
+	payload := append(encoding.EncodeB64(4, 2), "Jos\xe9"...)
+	in := NewIncoming([]byte("@A"), bytes.NewBuffer(payload))
+	require.Equal(t, "José", in.ReadString())
+}
@@ -0,0 +1,33 @@
+package packets
+
+import (
+	"sync"
+
+	"github.com/jtieri/habbgo/protocol/encoding"
+)
+
+// maxPooledSize is the largest buffer capacity, in bytes, that is handed back to a packet pool on Release.
+const maxPooledSize = 64 * 1024
+
+// maxHeaderId is the largest header ID that fits in a two byte Base64 header.
+const maxHeaderId = 4095
+
+var (
+	headersOnce sync.Once
+	headers     [maxHeaderId + 1]string
+)
+
+// headerString returns the two byte Base64 encoded header for headerId as a string.
+// Headers are encoded once and shared so that building a packet doesn't allocate a new header string every time.
+func headerString(headerId int) string {
+	if headerId < 0 || headerId > maxHeaderId {
+		return string(encoding.EncodeB64(headerId, 2))
+	}
+
+	headersOnce.Do(func() {
+		for i := range headers {
+			headers[i] = string(encoding.EncodeB64(i, 2))
+		}
+	})
+	return headers[headerId]
+}
@@ -2,6 +2,9 @@ package packets
 
 import (
 	"bytes"
+	"io"
+	"sync"
+
 	"github.com/jtieri/habbgo/protocol/encoding"
 )
 
@@ -10,14 +13,55 @@ type IncomingPacket struct {
 	Header   string
 	HeaderId int
 	Payload  *bytes.Buffer
+
+	limit io.LimitedReader // reused by ReadIncoming so reading a packet doesn't allocate
+}
+
+// incomingPool holds released IncomingPackets so that their buffers can be reused by ReadIncoming.
+var incomingPool = sync.Pool{
+	New: func() interface{} {
+		return &IncomingPacket{Payload: new(bytes.Buffer)}
+	},
 }
 
 // NewIncoming returns a pointer to a newly allocated IncomingPacket struct with its appropriate header information.
 func NewIncoming(rawHeader []byte, payload *bytes.Buffer) *IncomingPacket {
-	packet := &IncomingPacket{Header: string(rawHeader), HeaderId: encoding.DecodeB64(rawHeader), Payload: payload}
+	headerId := encoding.DecodeB64(rawHeader)
+	packet := &IncomingPacket{Header: headerString(headerId), HeaderId: headerId, Payload: payload}
 	return packet
 }
 
+// ReadIncoming reads a packet of length bytes, Base64 header included, from r into a packet taken from the
+// packet pool. Once the packet has been handled it should be handed back with Release.
+func ReadIncoming(r io.Reader, length int) (*IncomingPacket, error) {
+	packet := incomingPool.Get().(*IncomingPacket)
+	packet.Payload.Reset()
+	packet.limit = io.LimitedReader{R: r, N: int64(length)}
+
+	_, err := packet.Payload.ReadFrom(&packet.limit)
+	packet.limit.R = nil
+	if err == nil && packet.Payload.Len() < length {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		packet.Release()
+		return nil, err
+	}
+
+	rawHeader := packet.Payload.Next(2)
+	packet.HeaderId = encoding.DecodeB64(rawHeader)
+	packet.Header = headerString(packet.HeaderId)
+	return packet, nil
+}
+
+// Release returns the packet to the packet pool, the packet must not be used after calling Release.
+func (packet *IncomingPacket) Release() {
+	if packet.Payload.Cap() > maxPooledSize {
+		return
+	}
+	incomingPool.Put(packet)
+}
+
 // ReadB64 reads two bytes from the packets buffer and returns their Base64 decoded value as an integer.
 func (packet *IncomingPacket) ReadB64() int {
 	data := make([]byte, 2)
@@ -46,11 +90,18 @@ func (packet *IncomingPacket) ReadBool() bool {
 	return packet.ReadInt() == 1
 }
 
-// ReadString reads two bytes from the packets buffer to get a length of n and then returns a string of n bytes.
+// ReadString reads two bytes from the packets buffer to get a length of n and then returns a string of n bytes,
+// decoded from the client's character set into UTF-8.
 func (packet *IncomingPacket) ReadString() string {
 	length := packet.ReadB64()
 	message := packet.ReadBytes(length)
-	return string(message)
+	return CurrentCharset().Decode(message)
+}
+
+// Text returns the remaining bytes in the packets buffer decoded from the client's character set into UTF-8, for
+// packets whose body is plain text rather than encoded values.
+func (packet *IncomingPacket) Text() string {
+	return CurrentCharset().Decode(packet.Bytes())
 }
 
 // String returns the remaining bytes in the packets buffer as a string.
@@ -2,6 +2,8 @@ package packets
 
 import (
 	"bytes"
+	"sync"
+
 	"github.com/jtieri/habbgo/protocol/encoding"
 )
 
@@ -12,43 +14,78 @@ type OutgoingPacket struct {
 	Payload  *bytes.Buffer
 }
 
-// NewOutgoing returns a pointer to a newly allocated OutgoingPacket struct.
+// outgoingPool holds released OutgoingPackets so that their buffers can be reused by NewOutgoing.
+var outgoingPool = sync.Pool{
+	New: func() interface{} {
+		return &OutgoingPacket{Payload: new(bytes.Buffer)}
+	},
+}
+
+// NewOutgoing returns a pointer to an OutgoingPacket taken from the packet pool.
 // The two byte Base64 encoded header is written to the packets buffer on creation for quick composition of packets.
+// Once the packet has been written to a connection it should be handed back with Release.
 func NewOutgoing(headerId int) *OutgoingPacket {
-	header := encoding.EncodeB64(headerId, 2)
-	packet := &OutgoingPacket{Header: string(header), HeaderId: headerId, Payload: bytes.NewBuffer(header)}
+	packet := outgoingPool.Get().(*OutgoingPacket)
+	packet.Header = headerString(headerId)
+	packet.HeaderId = headerId
+
+	var header [2]byte
+	packet.Payload.Reset()
+	packet.Payload.Write(encoding.AppendB64(header[:0], headerId, 2))
 	return packet
 }
 
-// Write will write the passed in object as a string
+// Release returns the packet to the packet pool, the packet must not be used after calling Release.
+func (packet *OutgoingPacket) Release() {
+	// Don't hold on to buffers that grew unusually large, e.g. from a big navigator listing.
+	if packet.Payload.Cap() > maxPooledSize {
+		return
+	}
+	outgoingPool.Put(packet)
+}
+
+// Write will write the passed in object as a string, encoded in the client's character set.
 func (packet *OutgoingPacket) Write(s string) {
-	packet.Payload.Write([]byte(s))
+	packet.writeEncoded(s)
 }
 
-// WriteString writes a string to the packets buffer.
+// WriteString writes a string, encoded in the client's character set, to the packets buffer.
 func (packet *OutgoingPacket) WriteString(s string) {
-	packet.Payload.Write([]byte(s))
+	packet.writeEncoded(s)
 	packet.Payload.WriteByte(2) // FUSEv0.2.0 string parameter ending marker
 }
 
+// writeEncoded writes s to the packets buffer in the client's character set.
+// Plain ASCII, which is most of what the server sends, is written without transcoding.
+func (packet *OutgoingPacket) writeEncoded(s string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			packet.Payload.Write(CurrentCharset().Encode(s))
+			return
+		}
+	}
+	packet.Payload.WriteString(s)
+}
+
 // WriteInt writes a Vl64 encoded int to the packets buffer.
 func (packet *OutgoingPacket) WriteInt(i int) {
-	packet.Payload.Write(encoding.EncodeVl64(i))
+	var vl64 [6]byte
+	packet.Payload.Write(encoding.AppendVl64(vl64[:0], i))
 }
 
 // WriteBool writes a Vl64 encoded int representing true or false to the packets buffer.
 func (packet *OutgoingPacket) WriteBool(b bool) {
 	if b {
-		packet.WriteInt(1) // H
+		packet.WriteInt(1) // I
 	} else {
-		packet.WriteInt(0) // I
+		packet.WriteInt(0) // H
 	}
 }
 
 // WriteValue writes a key-value entry, separated by '=',to the packets buffer.
 func (packet *OutgoingPacket) WriteValue(key []byte, value []byte) {
 	packet.Payload.Write(key)
-	packet.Payload.Write([]byte("="))
+	packet.Payload.WriteByte('=')
 	packet.Payload.Write(value)
 	packet.Payload.WriteByte(13) // FUSEv0.2.0 key-value parameter ending marker
 }
@@ -56,7 +93,7 @@ func (packet *OutgoingPacket) WriteValue(key []byte, value []byte) {
 // WriteKeyValue writes a key-value pair, separated by ':', to the packets buffer.
 func (packet *OutgoingPacket) WriteKeyValue(key []byte, value []byte) {
 	packet.Payload.Write(key)
-	packet.Payload.Write([]byte(":"))
+	packet.Payload.WriteByte(':')
 	packet.Payload.Write(value)
 	packet.Payload.WriteByte(13) // FUSEv0.2.0 key-value parameter ending marker
 }
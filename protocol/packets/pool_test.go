@@ -0,0 +1,81 @@
+package packets
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jtieri/habbgo/protocol/encoding"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleasedPacketsStartClean(t *testing.T) {
+	packet := NewOutgoing(220)
+	packet.WriteString("The Hallways")
+	packet.WriteInt(1000)
+	packet.Release()
+
+	packet = NewOutgoing(6)
+	require.Equal(t, "@F", packet.Header)
+	require.Equal(t, 6, packet.HeaderId)
+	require.Equal(t, "@F", packet.String())
+}
+
+func TestReadIncoming(t *testing.T) {
+	payload := append(encoding.EncodeB64(4, 2), "@Itreebeard"...)
+	stream := bytes.NewReader(append(payload, "@@@"...))
+
+	packet, err := ReadIncoming(stream, len(payload))
+	require.NoError(t, err)
+	require.Equal(t, "@D", packet.Header)
+	require.Equal(t, 4, packet.HeaderId)
+	require.Equal(t, "treebeard", packet.ReadString())
+	packet.Release()
+
+	// The next packet's length header should be left in the stream.
+	require.Equal(t, 3, stream.Len())
+
+	_, err = ReadIncoming(bytes.NewReader(payload[:5]), len(payload))
+	require.Error(t, err)
+}
+
+// statusPacket builds a packet shaped like the room status updates that get broadcast every half a second.
+func statusPacket() *OutgoingPacket {
+	packet := NewOutgoing(34)
+	for i := 0; i < 10; i++ {
+		packet.WriteInt(i)
+		packet.WriteString("habbo")
+		packet.WriteInt(i * 3)
+		packet.WriteInt(i * 5)
+		packet.WriteString("/mv 5,6,0.0/")
+	}
+	packet.Finish()
+	return packet
+}
+
+func BenchmarkOutgoingPooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		statusPacket().Release()
+	}
+}
+
+func BenchmarkOutgoingUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = statusPacket() // never released, so every packet allocates a fresh buffer
+	}
+}
+
+func BenchmarkReadIncoming(b *testing.B) {
+	b.ReportAllocs()
+	payload := append(encoding.EncodeB64(4, 2), "@Itreebeard@Jtreebeard1"...)
+	reader := bytes.NewReader(payload)
+	for i := 0; i < b.N; i++ {
+		reader.Reset(payload)
+		packet, err := ReadIncoming(reader, len(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+		packet.Release()
+	}
+}
@@ -25,7 +25,10 @@ func RegisterCommands() (r *Router) {
 	r.RegisterHandshakeCommands()
 	r.RegisterRegistrationCommands()
 	r.RegisterPlayerCommands()
+	r.RegisterClubCommands()
 	r.RegisterNavigatorCommands()
+	r.RegisterRoomCommands()
+	r.RegisterModerationCommands()
 
 	return
 }
@@ -41,6 +44,7 @@ func (r *Router) RegisterHandshakeCommands() {
 	r.RegisteredCommands[204] = commands.SSO
 	r.RegisteredCommands[4] = commands.TRY_LOGIN
 	r.RegisteredCommands[207] = commands.SECRETKEY
+	r.RegisteredCommands[47] = commands.GET_PASSWORD
 }
 
 // RegisterRegistrationCommands registers the registration related Command handlers.
@@ -51,35 +55,69 @@ func (r *Router) RegisterRegistrationCommands() {
 	r.RegisteredCommands[203] = commands.APPROVE_PASSWORD
 	r.RegisteredCommands[197] = commands.APPROVEEMAIL
 	r.RegisteredCommands[43] = commands.REGISTER
+	r.RegisteredCommands[44] = commands.UPDATE
+	r.RegisteredCommands[149] = commands.UPDATE_ACCOUNT
+	r.RegisteredCommands[130] = commands.COPPA_REG_CHECKTIME
+	r.RegisteredCommands[131] = commands.COPPA_REG_GETREALTIME
+	r.RegisteredCommands[146] = commands.PARENT_EMAIL_REQUIRED
+	r.RegisteredCommands[147] = commands.VALIDATE_PARENT_EMAIL
+	r.RegisteredCommands[148] = commands.SEND_PARENT_EMAIL
 }
 
 // RegisterPlayerCommands registers the player related Command handlers.
 func (r *Router) RegisterPlayerCommands() {
 	r.RegisteredCommands[7] = commands.GET_INFO
 	r.RegisteredCommands[8] = commands.GET_CREDITS
+	r.RegisteredCommands[127] = commands.GETUSERCREDITLOG
+	r.RegisteredCommands[129] = commands.REDEEM_VOUCHER
+	r.RegisteredCommands[105] = commands.BTCKS
+	r.RegisteredCommands[100] = commands.GRPC
 	r.RegisteredCommands[157] = commands.GETAVAILABLEBADGES
+	r.RegisteredCommands[158] = commands.SETBADGE
 	r.RegisteredCommands[228] = commands.GET_SOUND_SETTING
+	r.RegisteredCommands[229] = commands.SET_SOUND_SETTING
 	r.RegisteredCommands[315] = commands.TestLatency
 }
 
+// RegisterClubCommands registers the Habbo Club related Command handlers.
+func (r *Router) RegisterClubCommands() {
+	r.RegisteredCommands[26] = commands.SCR_GET_USER_INFO
+	r.RegisteredCommands[190] = commands.SCR_BUY
+	r.RegisteredCommands[210] = commands.SCR_GIFT_APPROVAL
+}
+
 // RegisterNavigatorCommands registers the Navigator related Command handlers.
 func (r *Router) RegisterNavigatorCommands() {
 	r.RegisteredCommands[150] = commands.Navigate
-	// 151: GETUSERFLATCATS
-	// 21: GETFLATINFO
-	// 23: DELETEFLAT
-	// 24: UPDATEFLAT
-	// 25: SETFLATINFO
-	// 13: SBUSYF
-	// 152: GETFLATCAT
-	// 153: SETFLATCAT
+	r.RegisteredCommands[13] = commands.SBUSYF
+	r.RegisteredCommands[16] = commands.SUSERF
+	r.RegisteredCommands[17] = commands.SRCHF
+	r.RegisteredCommands[18] = commands.GETFVRF
+	r.RegisteredCommands[19] = commands.ADD_FAVORITE_ROOM
+	r.RegisteredCommands[20] = commands.DEL_FAVORITE_ROOM
+	r.RegisteredCommands[21] = commands.GETFLATINFO
+	r.RegisteredCommands[23] = commands.DELETEFLAT
+	r.RegisteredCommands[24] = commands.UPDATEFLAT
+	r.RegisteredCommands[25] = commands.SETFLATINFO
+	r.RegisteredCommands[29] = commands.CREATEFLAT
+	r.RegisteredCommands[151] = commands.GETUSERFLATCATS
+	r.RegisteredCommands[152] = commands.GETFLATCAT
+	r.RegisteredCommands[153] = commands.SETFLATCAT
+	r.RegisteredCommands[154] = commands.GETSPACENODEUSERS
+	r.RegisteredCommands[156] = commands.GETPARENTCHAIN
 	// 155: REMOVEALLRIGHTS
-	// 156: GETPARENTCHAIN
-	// 16: SUSERF
 	// 264: GET_RECOMMENDED_ROOMS
-	// 17: SRCHF
-	// 154: GETSPACENODEUSERS
-	// 18: GETFVRF
-	// 19: ADD_FAVORITE_ROOM
-	// 20: DEL_FAVORITE_ROOM
+}
+
+// RegisterRoomCommands registers the room entry related Command handlers.
+func (r *Router) RegisterRoomCommands() {
+	r.RegisteredCommands[2] = commands.ROOM_DIRECTORY
+	r.RegisteredCommands[57] = commands.TRYFLAT
+	r.RegisteredCommands[59] = commands.GOTOFLAT
+	r.RegisteredCommands[53] = commands.QUIT
+}
+
+// RegisterModerationCommands registers the moderation related Command handlers.
+func (r *Router) RegisterModerationCommands() {
+	r.RegisteredCommands[200] = commands.MODERATORACTION
 }
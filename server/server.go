@@ -9,9 +9,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/game/club"
 	"github.com/jtieri/habbgo/game/navigator"
 	"github.com/jtieri/habbgo/game/player"
+	"github.com/jtieri/habbgo/game/purse"
 	"github.com/jtieri/habbgo/game/room"
+	"github.com/jtieri/habbgo/mailer"
+	"github.com/jtieri/habbgo/protocol/packets"
 	"go.uber.org/zap"
 )
 
@@ -31,10 +36,28 @@ type Server struct {
 // Config is the game server configuration settings.
 // NOTE: to avoid circular dependencies we avoid cmd.Config and use a local reference to the game server config.
 type Config struct {
-	Host              string
-	Port              int
-	MaxConnsPerPlayer int
-	debug             bool
+	Host               string
+	Port               int
+	MaxConnsPerPlayer  int
+	Charset            string // character set spoken by the client, windows-1252, iso-8859-1 or utf-8
+	UnmappableChars    string // what to do with characters the Charset can't represent, replace or strip
+	PasswordHasher     string // algorithm used to hash new passwords, argon2id or bcrypt
+	IssueMachineIds    bool   // give clients that send an empty UNIQUEID a fresh machine ID
+	FigureData         string // path to a figure data file replacing the built in clothing sets, if set
+	Mail               mailer.Config
+	ResetURL           string // web page password reset links point to
+	Coppa              int    // 0 off, 1 under age players need a parent's agreement, 2 under age players can't register
+	CoppaAge           int    // players younger than this are under age
+	RequireParentEmail bool   // restrict under age accounts until a parent confirms them by email
+	SendParentEmail    bool   // ask restricted players for their parent's email address again when they log in
+	ParentConfirmURL   string // web page parent confirmation links point to
+	DefaultPreferences player.Preferences
+	Club               club.Config  // Habbo Club prices & monthly gifts
+	VoucherEnabled     bool         // let players redeem vouchers in the purse
+	Purse              purse.Config // ticket & film prices
+	MaxFavouriteRooms  int          // most rooms a player can add to their favourites
+	MaxRoomsPerPlayer  int          // most guest rooms a player can own
+	debug              bool
 }
 
 // New returns a pointer to a newly allocated Server struct.
@@ -50,7 +73,35 @@ func New(
 			Host:              host,
 			Port:              port,
 			MaxConnsPerPlayer: maxConnsPerPlayer,
+			Charset:           "windows-1252",
+			UnmappableChars:   "replace",
+			PasswordHasher:    "argon2id",
+			IssueMachineIds:   true,
+			VoucherEnabled:    true,
+			MaxFavouriteRooms: 30,
+			MaxRoomsPerPlayer: 25,
+			ResetURL:          "http://127.0.0.1:8080/reset",
+			CoppaAge:          13,
+			ParentConfirmURL:  "http://127.0.0.1:8080/parent/confirm",
 			debug:             debug,
+			DefaultPreferences: player.Preferences{
+				SoundEnabled:         true,
+				AcceptFriendRequests: true,
+			},
+			Club: club.Config{
+				Options: []club.Option{{Periods: 1, Credits: 25}, {Periods: 3, Credits: 60}, {Periods: 6, Credits: 105}},
+				Gifts:   []string{"club_sofa", "hc_chr", "hc_tbl", "hc_lmp", "hc_bkshlf", "hc_dsk", "hc_crpt", "hc_crtn"},
+			},
+			Purse: purse.Config{
+				Tickets: []purse.Bundle{{Amount: 2, Credits: 1}, {Amount: 20, Credits: 6}},
+				Film:    []purse.Bundle{{Amount: 5, Credits: 6, SaleCode: "film"}},
+			},
+			Mail: mailer.Config{
+				Transport: "outbox",
+				From:      "habbgo@localhost",
+				Outbox:    "outbox",
+				SMTPPort:  587,
+			},
 		},
 		database: database,
 		mux:      sync.Mutex{},
@@ -58,13 +109,45 @@ func New(
 	}
 }
 
+// Config returns the Server's configuration so that the defaults set in New can be overridden before calling Start.
+func (server *Server) Config() *Config {
+	return server.config
+}
+
 // Start will start the Server's main loop which listens for incoming TCP connections.
 func (server *Server) Start(ctx context.Context) chan error {
 	errorChan := make(chan error, 1)
+
+	if err := server.setCharset(); err != nil {
+		errorChan <- err
+		close(errorChan)
+		return errorChan
+	}
+
 	go server.HandleConnections(ctx, errorChan)
 	return errorChan
 }
 
+// setCharset configures the character set used to transcode strings in incoming and outgoing packets.
+func (server *Server) setCharset() error {
+	policy, err := packets.ParseUnmappablePolicy(server.config.UnmappableChars)
+	if err != nil {
+		return err
+	}
+
+	charset, err := packets.NewCharset(server.config.Charset, policy)
+	if err != nil {
+		return err
+	}
+
+	packets.SetCharset(charset)
+	server.log.Info("Using client charset",
+		zap.String("charset", charset.Name),
+		zap.String("unmappable_chars", server.config.UnmappableChars),
+	)
+	return nil
+}
+
 // HandleConnections listens for new incoming connections and creates a new session
 // for valid requests.
 func (server *Server) HandleConnections(ctx context.Context, errorChan chan error) {
@@ -172,6 +255,10 @@ func (server *Server) Stop() {
 		session.Close()
 	}
 
+	if server.services != nil {
+		server.services.Players.Stop()
+	}
+
 	server.log.Info("Shutting down game server")
 	os.Exit(0)
 }
@@ -202,8 +289,48 @@ func (server *Server) BuildGameServices() {
 	)
 	rs.Build()
 
+	hasher, err := crypto.NewPasswordHasher(server.config.PasswordHasher)
+	if err != nil {
+		server.log.Warn("Invalid password hasher configured, falling back to argon2id",
+			zap.String("password_hasher", server.config.PasswordHasher),
+			zap.Error(err),
+		)
+		hasher = crypto.DefaultArgon2id()
+	}
+
+	mail, err := mailer.New(&server.config.Mail)
+	if err != nil {
+		server.log.Warn("Invalid mail transport configured, falling back to the outbox",
+			zap.String("transport", server.config.Mail.Transport),
+			zap.Error(err),
+		)
+		mail = &mailer.Outbox{Dir: server.config.Mail.Outbox, From: server.config.Mail.From}
+	}
+
 	ps := player.NewPlayerService(
-		server.log.With(zap.String("service_name", "player_service")))
+		server.log.With(zap.String("service_name", "player_service")),
+		server.database,
+		hasher,
+		mail,
+		&player.Config{
+			IssueMachineIds: server.config.IssueMachineIds,
+			FigureData:      server.config.FigureData,
+			ResetURL:        server.config.ResetURL,
+
+			Coppa:              server.config.Coppa,
+			CoppaAge:           server.config.CoppaAge,
+			RequireParentEmail: server.config.RequireParentEmail,
+			SendParentEmail:    server.config.SendParentEmail,
+			ParentConfirmURL:   server.config.ParentConfirmURL,
+
+			DefaultPreferences: server.config.DefaultPreferences,
+			Club:               server.config.Club,
+			VoucherEnabled:     server.config.VoucherEnabled,
+			Purse:              server.config.Purse,
+			MaxFavouriteRooms:  server.config.MaxFavouriteRooms,
+			MaxRoomsPerPlayer:  server.config.MaxRoomsPerPlayer,
+		},
+	)
 	ps.Build()
 
 	server.services = &Services{
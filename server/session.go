@@ -2,7 +2,7 @@ package server
 
 import (
 	"bufio"
-	"bytes"
+	"io"
 	"net"
 	"reflect"
 	"runtime"
@@ -23,6 +23,7 @@ type Session struct {
 	active     bool
 	server     *Server
 	router     *Router
+	player     *player.Player
 	log        *zap.Logger
 }
 
@@ -53,68 +54,64 @@ func (session *Session) Listen() {
 		session.server.database,
 		session.server.services,
 	)
+	session.player = p
 	reader := bufio.NewReader(session.connection)
 
 	// Send packet with Base64 header @@ to initialize connection with client.
 	session.Send(messages.HELLO, messages.HELLO())
 
+	// Turn away connections from banned IP addresses before handling any of their packets.
+	if b := p.Banned(); b != nil {
+		session.log.Info("Banned address tried to connect",
+			zap.String("session_address", session.Address()),
+			zap.Int("ban_id", b.ID),
+		)
+		session.Send(messages.USER_BANNED, messages.USER_BANNED(b.Message()))
+		session.Close()
+		return
+	}
+
 	// Listen for incoming packets from a player's session.
+	// The encoded length is read into the same array every time to avoid allocating per packet.
+	var encodedLen [3]byte
 	for {
 		// Attempt to read three bytes,
 		// client->server packets in FUSEv0.2.0 begin with 3 byte Base64 encoded packet length.
-		encodedLen := make([]byte, 3)
-
-		for i := 0; i < 3; i++ {
-			b, err := reader.ReadByte()
-			if err != nil {
-				// If the network connection is closed, it's because the server closed the Session
-				// which means we don't need to log again or call session.Close
-				if strings.Contains(err.Error(), "use of closed network connection") {
-					return
-				}
-
-				session.log.Warn("Error reading encoded packet length from session",
-					zap.String("session_address", session.Address()),
-					zap.Error(err),
-				)
-				session.Close()
+		if _, err := io.ReadFull(reader, encodedLen[:]); err != nil {
+			// If the network connection is closed, it's because the server closed the Session
+			// which means we don't need to log again or call session.Close
+			if strings.Contains(err.Error(), "use of closed network connection") {
 				return
 			}
-			encodedLen[i] = b
-		}
-		packetLen := encoding.DecodeB64(encodedLen)
 
-		// Check if data is junk before handling.
-		rawPacket := make([]byte, packetLen)
-		bytesRead, err := reader.Read(rawPacket)
-		switch {
-		case err != nil:
-			session.log.Warn("Error reading packet data from session",
+			session.log.Warn("Error reading encoded packet length from session",
 				zap.String("session_address", session.Address()),
 				zap.Error(err),
 			)
 			session.Close()
 			return
-		case packetLen == 0:
+		}
+		packetLen := encoding.DecodeB64(encodedLen[:])
+
+		// Check if data is junk before handling.
+		if packetLen == 0 {
 			session.log.Info("Junk packet received")
 			continue
-		case bytesRead < packetLen:
-			session.log.Info("Packet length mismatch",
-				zap.Int("expected_length", packetLen),
-				zap.Int("got_length", bytesRead),
-			)
-			continue
 		}
 
-		// Get Base64 encoded packet header.
-		payload := bytes.NewBuffer(rawPacket)
-		rawHeader := make([]byte, 2)
-		for i := 0; i < 2; i++ {
-			rawHeader[i], _ = payload.ReadByte()
+		// The packet, along with its Base64 encoded header, is read into a pooled buffer
+		// which is released once the packet has been handled.
+		packet, err := packets.ReadIncoming(reader, packetLen)
+		if err != nil {
+			session.log.Warn("Error reading packet data from session",
+				zap.String("session_address", session.Address()),
+				zap.Int("expected_length", packetLen),
+				zap.Error(err),
+			)
+			session.Close()
+			return
 		}
 
-		packet := packets.NewIncoming(rawHeader, payload)
-
 		// Handle packets coming in from the Player's Session.
 		go session.Handle(p, packet)
 	}
@@ -122,8 +119,10 @@ func (session *Session) Listen() {
 
 // Handle attempts to handle an incoming packet from a player.Player's Session.
 // If the packet is not registered in the Router with an appropriate handler,
-// the packet is ignored.
+// the packet is ignored. The packet is released back to the packet pool once handled.
 func (session *Session) Handle(p *player.Player, packet *packets.IncomingPacket) {
+	defer packet.Release()
+
 	handler, found := p.Session.GetPacketCommand(packet.HeaderId)
 
 	if found {
@@ -136,27 +135,29 @@ func (session *Session) Handle(p *player.Player, packet *packets.IncomingPacket)
 		// If the user is still logging in we don't have their username for logging so,
 		// we check to see if we should log it or not.
 		// TODO possibly just remove this and never log usernames
-		switch {
-		case p.Details.Username != "":
-			session.log.Debug("Incoming Packet",
-				zap.String("player_name", p.Details.Username),
-				zap.String("packet_name", handlerName),
-				zap.String("packet_header", packet.Header),
-				zap.Int("header_id", packet.HeaderId),
-				zap.String("payload", packet.Payload.String()),
-			)
-		default:
-			session.log.Debug("Incoming Packet",
-				zap.String("packet_name", handlerName),
-				zap.String("packet_header", packet.Header),
-				zap.Int("header_id", packet.HeaderId),
-				zap.String("payload", packet.Payload.String()),
-			)
+		if ce := session.log.Check(zap.DebugLevel, "Incoming Packet"); ce != nil {
+			switch {
+			case p.Details.Username != "":
+				ce.Write(
+					zap.String("player_name", p.Details.Username),
+					zap.String("packet_name", handlerName),
+					zap.String("packet_header", packet.Header),
+					zap.Int("header_id", packet.HeaderId),
+					zap.String("payload", packet.Payload.String()),
+				)
+			default:
+				ce.Write(
+					zap.String("packet_name", handlerName),
+					zap.String("packet_header", packet.Header),
+					zap.Int("header_id", packet.HeaderId),
+					zap.String("payload", packet.Payload.String()),
+				)
+			}
 		}
 
 		handler(p, packet)
-	} else {
-		session.log.Debug("Incoming Packet",
+	} else if ce := session.log.Check(zap.DebugLevel, "Incoming Packet"); ce != nil {
+		ce.Write(
 			zap.String("player_name", p.Details.Username),
 			zap.String("packet_header", packet.Header),
 			zap.Int("header_id", packet.HeaderId),
@@ -167,8 +168,10 @@ func (session *Session) Handle(p *player.Player, packet *packets.IncomingPacket)
 }
 
 // Send finalizes an outgoing packet with 0x01 and then attempts to write the packet to a Session's buffer
-// before flushing the buffer.
+// before flushing the buffer. The packet is released back to the packet pool afterwards and must not be reused.
 func (session *Session) Send(caller interface{}, packet *packets.OutgoingPacket) {
+	defer packet.Release()
+
 	packet.Finish()
 	session.buffer.mux.Lock()
 	defer session.buffer.mux.Unlock()
@@ -199,16 +202,22 @@ func (session *Session) Send(caller interface{}, packet *packets.OutgoingPacket)
 		return
 	}
 
-	session.log.Debug("Outgoing Packet",
-		zap.String("packet_name", GetPacketHandlerName(caller)),
-		zap.String("packet_header", packet.Header),
-		zap.Int("header_id", packet.HeaderId),
-		zap.String("payload", packet.Payload.String()),
-	)
+	// Check the log level first so the handler name and payload are only built when debugging.
+	if ce := session.log.Check(zap.DebugLevel, "Outgoing Packet"); ce != nil {
+		ce.Write(
+			zap.String("packet_name", GetPacketHandlerName(caller)),
+			zap.String("packet_header", packet.Header),
+			zap.Int("header_id", packet.HeaderId),
+			zap.String("payload", packet.Payload.String()),
+		)
+	}
 }
 
 // Queue finalizes an outgoing packet with 0x01 and then attempts to write the packet to a Session's buffer.
+// The packet is released back to the packet pool afterwards and must not be reused.
 func (session *Session) Queue(packet *packets.OutgoingPacket) {
+	defer packet.Release()
+
 	packet.Finish()
 	session.buffer.mux.Lock()
 	defer session.buffer.mux.Unlock()
@@ -216,7 +225,6 @@ func (session *Session) Queue(packet *packets.OutgoingPacket) {
 	_, err := session.buffer.buff.Write(packet.Payload.Bytes())
 	if err != nil {
 		session.log.Warn("Error writing packet to session buffer",
-			zap.String("packet_name", GetPacketHandlerName(packet)),
 			zap.String("packet_header", packet.Header),
 			zap.Int("header_id", packet.HeaderId),
 			zap.String("payload", packet.Payload.String()),
@@ -225,32 +233,34 @@ func (session *Session) Queue(packet *packets.OutgoingPacket) {
 		session.Close()
 		return
 	}
+
+	if ce := session.log.Check(zap.DebugLevel, "Queued Packet"); ce != nil {
+		ce.Write(
+			zap.String("packet_header", packet.Header),
+			zap.Int("header_id", packet.HeaderId),
+			zap.String("payload", packet.Payload.String()),
+		)
+	}
 }
 
-// Flush attempts flush the packet to a Session's buffer.
-func (session *Session) Flush(caller interface{}, packet *packets.OutgoingPacket) {
+// Flush attempts to flush the packets queued in a Session's buffer to its connection.
+func (session *Session) Flush(caller interface{}) {
 	session.buffer.mux.Lock()
 	defer session.buffer.mux.Unlock()
 
 	err := session.buffer.buff.Flush()
 	if err != nil {
-		session.log.Warn("Error sending packet to session",
+		session.log.Warn("Error sending packets to session",
 			zap.String("packet_name", GetPacketHandlerName(caller)),
-			zap.String("packet_header", packet.Header),
-			zap.Int("header_id", packet.HeaderId),
-			zap.String("payload", packet.Payload.String()),
 			zap.Error(err),
 		)
 		session.Close()
 		return
 	}
 
-	session.log.Debug("Outgoing Packet",
-		zap.String("packet_name", GetPacketHandlerName(caller)),
-		zap.String("packet_header", packet.Header),
-		zap.Int("header_id", packet.HeaderId),
-		zap.String("payload", packet.Payload.String()),
-	)
+	if ce := session.log.Check(zap.DebugLevel, "Flushed queued packets"); ce != nil {
+		ce.Write(zap.String("packet_name", GetPacketHandlerName(caller)))
+	}
 }
 
 // GetPacketCommand attempts to retrieve a registered Command from the Router.
@@ -276,6 +286,10 @@ func (session *Session) Close() {
 
 	session.server.RemoveSession(session)
 	session.active = false
+
+	if session.player != nil {
+		session.player.Logout()
+	}
 }
 
 // GetPacketHandlerName is a hacky way to get the name of the incoming/outgoing packet function call,
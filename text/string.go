@@ -3,18 +3,27 @@ package text
 import (
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 func Filter(s string) string {
 	output := strings.Replace(s, string(rune(1)), "", -1)
-	output = strings.Replace(s, string(rune(2)), "", -1)
-	output = strings.Replace(s, string(rune(9)), "", -1)
-	output = strings.Replace(s, string(rune(10)), "", -1)
-	output = strings.Replace(s, string(rune(12)), "", -1)
-	output = strings.Replace(s, string(rune(13)), "", -1) // filter newline chars too
+	output = strings.Replace(output, string(rune(2)), "", -1)
+	output = strings.Replace(output, string(rune(9)), "", -1)
+	output = strings.Replace(output, string(rune(10)), "", -1)
+	output = strings.Replace(output, string(rune(12)), "", -1)
+	output = strings.Replace(output, string(rune(13)), "", -1) // filter newline chars too
 	return output
 }
 
+// Truncate shortens s to at most max runes, so that multi byte characters aren't cut in half.
+func Truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
 // 1234567890qwertyuiopasdfghjklzxcvbnm_-+=?!@:.,$
 func ContainsAllowedChars(toTest, allowedChars string) bool {
 	for _, v := range toTest {
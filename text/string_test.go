@@ -18,3 +18,16 @@ func TestContainsAllowedChars(t *testing.T) {
 	require.False(t, ContainsAllowedChars("!---the winner---!", allowedChars))
 	require.True(t, ContainsAllowedChars("____________", allowedChars))
 }
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "habbo", Truncate("habbo", 5))
+	require.Equal(t, "hab", Truncate("habbo", 3))
+	require.Equal(t, "café", Truncate("café hotel", 4))
+	require.Equal(t, "ééé", Truncate("éééé", 3))
+}
+
+func TestFilter(t *testing.T) {
+	require.Equal(t, "My Room", Filter("My Room"))
+	require.Equal(t, "MyRoom", Filter("My\tRoom\r\n"))
+	require.Equal(t, "abc", Filter("\x01a\x02b\tc\n\x0c\r"))
+}
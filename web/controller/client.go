@@ -1,7 +1,32 @@
 package controller
 
-import "github.com/gin-gonic/gin"
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"os"
 
+	"github.com/gin-gonic/gin"
+)
+
+const clientPage = "./client/client.html"
+
+// GetClient serves the client page. If the request carries an SSO ticket in the ticket query parameter,
+// the ticket is passed on to the client so that it logs in with it instead of asking for credentials.
 func GetClient(c *gin.Context) {
-	c.File("./client/client.html")
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		c.File(clientPage)
+		return
+	}
+
+	page, err := os.ReadFile(clientPage)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	param := []byte(`<param name="sw6" value="use.sso.ticket=1;sso.ticket=` + html.EscapeString(ticket) + `">` + "\n      </object>")
+	page = bytes.Replace(page, []byte("</object>"), param, 1)
+	c.Data(http.StatusOK, "text/html; charset=windows-1252", page)
 }
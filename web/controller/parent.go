@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"database/sql"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtieri/habbgo/game/player"
+)
+
+var parentPage = template.Must(template.New("parent").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Confirm your child's account</title></head>
+<body>
+  <h1>Confirm your child's account</h1>
+  {{if .Message}}<p>{{.Message}}</p>{{end}}
+  {{if .Token}}
+  <form method="post" action="/parent/confirm">
+    <input type="hidden" name="token" value="{{.Token}}">
+    <p>Your child asked for their habbgo account to be confirmed. Until you confirm it, some features are disabled.</p>
+    <button type="submit">Confirm the account</button>
+  </form>
+  {{end}}
+</body>
+</html>
+`))
+
+// ParentController serves the page parent confirmation emails link to.
+type ParentController struct {
+	repo *player.ParentRepo
+}
+
+func NewParentController(db *sql.DB) *ParentController {
+	return &ParentController{repo: player.NewParentRepo(db)}
+}
+
+// GetConfirm shows the form confirming the account with the token from the confirmation link. Nothing is confirmed
+// until the form is posted, so mail scanners following the link can't confirm accounts for parents.
+func (pc *ParentController) GetConfirm(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		pc.render(c, http.StatusBadRequest, "", "This confirmation link is invalid.")
+		return
+	}
+	pc.render(c, http.StatusOK, token, "")
+}
+
+// PostConfirm consumes the confirmation token, lifting the restrictions on the child's account.
+func (pc *ParentController) PostConfirm(c *gin.Context) {
+	username, err := pc.repo.Confirm(c.PostForm("token"))
+	switch {
+	case err == player.ErrInvalidConfirmation:
+		pc.render(c, http.StatusBadRequest, "", "This confirmation link is invalid or has expired.")
+	case err != nil:
+		pc.render(c, http.StatusInternalServerError, c.PostForm("token"), "Failed to confirm the account, please try again.")
+	default:
+		pc.render(c, http.StatusOK, "", "Thank you, the account "+username+" has been confirmed.")
+	}
+}
+
+func (pc *ParentController) render(c *gin.Context, status int, token, message string) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = parentPage.Execute(c.Writer, gin.H{"Token": token, "Message": message})
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"database/sql"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/game/player"
+)
+
+var resetPage = template.Must(template.New("reset").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Reset your password</title></head>
+<body>
+  <h1>Reset your password</h1>
+  {{if .Message}}<p>{{.Message}}</p>{{end}}
+  {{if .Token}}
+  <form method="post" action="/reset">
+    <input type="hidden" name="token" value="{{.Token}}">
+    <label>New password <input type="password" name="password"></label>
+    <label>Repeat it <input type="password" name="confirm"></label>
+    <button type="submit">Save</button>
+  </form>
+  {{end}}
+</body>
+</html>
+`))
+
+// ResetController serves the page password reset emails link to, where players choose a new password.
+type ResetController struct {
+	repo   *player.ResetRepo
+	hasher crypto.PasswordHasher
+	guard  *player.PasswordGuard
+}
+
+func NewResetController(db *sql.DB, hasher crypto.PasswordHasher) *ResetController {
+	return &ResetController{
+		repo:   player.NewResetRepo(db),
+		hasher: hasher,
+		guard:  player.NewPasswordGuard(player.LoginAttempts, player.LoginAttemptWindow),
+	}
+}
+
+// GetReset shows the form for choosing a new password with the token from the reset link.
+func (rc *ResetController) GetReset(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		rc.render(c, http.StatusBadRequest, "", "This password reset link is invalid.")
+		return
+	}
+	rc.render(c, http.StatusOK, token, "")
+}
+
+// PostReset consumes the reset token and stores the new password. The token is checked before the password is
+// hashed, and each IP address can only try player.LoginAttempts times per player.LoginAttemptWindow.
+func (rc *ResetController) PostReset(c *gin.Context) {
+	token := c.PostForm("token")
+	password := c.PostForm("password")
+
+	ip, _ := c.RemoteIP()
+	if ip == nil || !rc.guard.Allow(ip.String()) {
+		rc.render(c, http.StatusTooManyRequests, token, "Too many attempts, please wait a minute and try again.")
+		return
+	}
+
+	username, err := rc.repo.ResetUsername(token)
+	switch {
+	case err == player.ErrInvalidResetToken:
+		rc.render(c, http.StatusBadRequest, "", "This password reset link is invalid or has expired.")
+		return
+	case err != nil:
+		rc.render(c, http.StatusInternalServerError, token, "Failed to save your password, please try again.")
+		return
+	}
+
+	if password != c.PostForm("confirm") {
+		rc.render(c, http.StatusBadRequest, token, "The passwords don't match.")
+		return
+	}
+	if err := player.ValidatePassword(username, password); err != nil {
+		rc.render(c, http.StatusBadRequest, token, passwordProblem(err))
+		return
+	}
+
+	hash, err := rc.guard.Hash(rc.hasher, password)
+	if err != nil {
+		rc.render(c, http.StatusInternalServerError, token, "Failed to save your password, please try again.")
+		return
+	}
+
+	err = rc.repo.ResetPassword(token, hash)
+	switch {
+	case err == player.ErrInvalidResetToken:
+		rc.render(c, http.StatusBadRequest, "", "This password reset link is invalid or has expired.")
+	case err != nil:
+		rc.render(c, http.StatusInternalServerError, token, "Failed to save your password, please try again.")
+	default:
+		rc.render(c, http.StatusOK, "", "Your password has been changed, you can now log in with it.")
+	}
+}
+
+// passwordProblem explains why player.ValidatePassword rejected a password.
+func passwordProblem(err error) string {
+	switch err {
+	case player.ErrPasswordTooShort, player.ErrPasswordTooLong:
+		return fmt.Sprintf("Your password must be %d to %d characters long.", player.MinPasswordLength,
+			player.MaxPasswordLength)
+	case player.ErrPasswordUnacceptable:
+		return "Your password can only contain letters, numbers and " + strings.TrimLeft(player.AllowedChars,
+			"1234567890qwertyuiopasdfghjklzxcvbnm") + "."
+	case player.ErrPasswordHasNoNumber:
+		return "Your password must contain a number."
+	case player.ErrPasswordSimilarToName:
+		return "Your password can't be the same as your username."
+	default:
+		return "Please choose another password."
+	}
+}
+
+func (rc *ResetController) render(c *gin.Context, status int, token, message string) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = resetPage.Execute(c.Writer, gin.H{"Token": token, "Message": message})
+}
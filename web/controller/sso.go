@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtieri/habbgo/game/player"
+)
+
+// SSOSecretHeader is the request header that carries the secret shared with the site minting tickets.
+const SSOSecretHeader = "X-SSO-Secret"
+
+// SSOController mints SSO tickets so that a website login can hand players straight to the client
+// without them having to type their credentials in again.
+type SSOController struct {
+	repo   *player.SSORepo
+	secret string
+}
+
+func NewSSOController(db *sql.DB, secret string) *SSOController {
+	return &SSOController{repo: player.NewSSORepo(db), secret: secret}
+}
+
+// MintTicket mints a ticket for the player named in the username form field, bound to the IP address in the
+// ip form field. It's meant to be called by the site running alongside habbgo, which then sends the player to the
+// client page with ?ticket=<ticket>, so only requests made from the loopback interface with the shared secret in
+// the SSOSecretHeader header are served. The peer's address is checked rather than any forwarded header.
+func (sc *SSOController) MintTicket(c *gin.Context) {
+	if ip, _ := c.RemoteIP(); ip == nil || !ip.IsLoopback() {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	secret := c.GetHeader(SSOSecretHeader)
+	if sc.secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(sc.secret)) != 1 {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	username := c.PostForm("username")
+	if username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username is required"})
+		return
+	}
+
+	ip := net.ParseIP(c.PostForm("ip"))
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a valid ip is required"})
+		return
+	}
+
+	ticket, err := sc.repo.MintTicket(username, ip.String(), player.DefaultSSOTicketLifetime)
+	switch {
+	case err == player.ErrPlayerNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mint ticket"})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"ticket":     ticket,
+			"expires_in": int(player.DefaultSSOTicketLifetime.Seconds()),
+		})
+	}
+}
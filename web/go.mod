@@ -5,4 +5,8 @@ go 1.16
 require (
 	github.com/gin-gonic/contrib v0.0.0-20201101042839-6a891bf89f19
 	github.com/gin-gonic/gin v1.7.4
+	github.com/jtieri/habbgo v0.0.0
+	github.com/lib/pq v1.10.4
 )
+
+replace github.com/jtieri/habbgo => ../
@@ -1,14 +1,43 @@
 package main
 
 import (
-	"github.com/jtieri/habbgo/web/server"
+	"database/sql"
 	"log"
+	"os"
+
+	"github.com/jtieri/habbgo/crypto"
+	"github.com/jtieri/habbgo/web/server"
+	_ "github.com/lib/pq"
 )
 
 func main() {
 	log.Println("Starting the web server.... ")
-	webServer := server.New()
-	err := webServer.Start("127.0.0.1", 8080)
+
+	// The database is optional, without it the client is still served but SSO tickets can't be minted.
+	var db *sql.DB
+	if dsn := os.Getenv("HABBGO_DATABASE_URL"); dsn != "" {
+		var err error
+		db, err = sql.Open("postgres", dsn)
+		if err != nil {
+			log.Fatal("Failed to open the database due to:  " + err.Error())
+		}
+		defer db.Close()
+	}
+
+	// New passwords set through the reset page are hashed like the game server hashes them, argon2id by default.
+	hasher, err := crypto.NewPasswordHasher(os.Getenv("HABBGO_PASSWORD_HASHER"))
+	if err != nil {
+		log.Fatal("Failed to create the password hasher due to:  " + err.Error())
+	}
+
+	// Sites minting SSO tickets must send this secret, without it no tickets are minted.
+	ssoSecret := os.Getenv("HABBGO_SSO_SECRET")
+	if db != nil && ssoSecret == "" {
+		log.Println("HABBGO_SSO_SECRET is not set, SSO tickets can't be minted.")
+	}
+
+	webServer := server.New(db, hasher, ssoSecret)
+	err = webServer.Start("127.0.0.1", 8080)
 	if err != nil {
 		log.Fatal("Failed to start the web server due to:  " + err.Error())
 	}
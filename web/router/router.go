@@ -1,15 +1,33 @@
 package router
 
 import (
+	"database/sql"
+
 	"github.com/gin-gonic/contrib/static"
 	"github.com/gin-gonic/gin"
+	"github.com/jtieri/habbgo/crypto"
 	"github.com/jtieri/habbgo/web/controller"
 )
 
-func SetupRouter() *gin.Engine {
+// SetupRouter registers the web routes. The SSO, password reset & parent confirmation routes are only available
+// when a database is configured, and tickets are only minted for callers presenting ssoSecret.
+func SetupRouter(db *sql.DB, hasher crypto.PasswordHasher, ssoSecret string) *gin.Engine {
 	router := gin.Default()
 	router.Use(static.Serve("/", static.LocalFile("client/", true))) // Enable static client files
 	router.GET("/", controller.GetClient)
 
+	if db != nil {
+		sso := controller.NewSSOController(db, ssoSecret)
+		router.POST("/sso", sso.MintTicket)
+
+		reset := controller.NewResetController(db, hasher)
+		router.GET("/reset", reset.GetReset)
+		router.POST("/reset", reset.PostReset)
+
+		parent := controller.NewParentController(db)
+		router.GET("/parent/confirm", parent.GetConfirm)
+		router.POST("/parent/confirm", parent.PostConfirm)
+	}
+
 	return router
 }
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"database/sql"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jtieri/habbgo/crypto"
 	"github.com/jtieri/habbgo/web/router"
 )
 
@@ -10,8 +12,8 @@ type WebServer struct {
 	Router *gin.Engine
 }
 
-func New() *WebServer {
-	r := router.SetupRouter()
+func New(db *sql.DB, hasher crypto.PasswordHasher, ssoSecret string) *WebServer {
+	r := router.SetupRouter(db, hasher, ssoSecret)
 
 	return &WebServer{
 		Router: r,